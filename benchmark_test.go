@@ -0,0 +1,86 @@
+package errkit_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kanisterio/errkit"
+)
+
+func newDeepChain(depth int) error {
+	err := errkit.New("Root cause", "key", "value")
+	for i := 0; i < depth; i++ {
+		err = errkit.Wrap(err, "Wrapped error")
+	}
+	return err
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = errkit.New("Some error")
+	}
+}
+
+func BenchmarkNewWithDetails(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = errkit.New("Some error", "key", "value", "number", 1)
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	cause := errkit.New("Some error")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = errkit.Wrap(cause, "Wrapped error")
+	}
+}
+
+func BenchmarkWrapStackCapture(b *testing.B) {
+	cause := errkit.New("Some error")
+	for _, bc := range []struct {
+		name string
+		wrap func(err error, message string, details ...any) error
+	}{
+		{"stack", errkit.Wrap},
+		{"no-stack", errkit.WrapNoStack},
+		{"skip-if-caused", errkit.Builder{}.Config(errkit.Config{SkipStackIfCaused: true}).Wrap},
+		{"sampling=10", errkit.Builder{}.Config(errkit.Config{StackSampling: 10}).Wrap},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = bc.wrap(cause, "Wrapped error")
+			}
+		})
+	}
+}
+
+func BenchmarkError(b *testing.B) {
+	for _, depth := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			err := newDeepChain(depth)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = err.Error()
+			}
+		})
+	}
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	for _, depth := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			err := newDeepChain(depth)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = json.Marshal(err)
+			}
+		})
+	}
+}
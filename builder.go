@@ -0,0 +1,134 @@
+package errkit
+
+// componentKey is the detail name used to store the component name of a Builder.
+const componentKey = "component"
+
+// Builder creates errors which share the same preset details, component name
+// and configuration. It is useful when every error created within some operation
+// has to carry the same details, e.g.:
+//
+//	b := errkit.With("namespace", ns, "actionset", name).Component("controller")
+//	...
+//	return b.Wrap(err, "Failed to execute phase", "phase", phase)
+//
+// Builder is immutable, every method returns a new Builder, so it is safe to share
+// it between goroutines. The zero value is ready to use.
+//
+// Details are merged in the following order, later values override earlier ones
+// having the same name:
+//  1. preset details, in the order they were added with With
+//  2. component name, stored as the "component" detail
+//  3. details passed to the method creating an error
+type Builder struct {
+	details   ErrorDetails
+	component string
+	severity  Severity
+	config    *Config
+}
+
+// With returns a Builder with the given preset details.
+// Details are passed the same way as to New, either as key/value pairs or as ErrorDetails.
+func With(details ...any) Builder {
+	return Builder{}.With(details...)
+}
+
+// With returns a copy of the Builder with the given details added to preset ones.
+func (b Builder) With(details ...any) Builder {
+	b.details = mergeDetails(b.details, ToErrorDetails(details))
+	return b
+}
+
+// Component returns a copy of the Builder with the given component name.
+func (b Builder) Component(name string) Builder {
+	b.component = name
+	return b
+}
+
+// Severity returns a copy of the Builder which creates errors with the given severity.
+func (b Builder) Severity(severity Severity) Builder {
+	b.severity = severity
+	return b
+}
+
+// Config returns a copy of the Builder which creates errors using the given
+// configuration instead of the default one.
+func (b Builder) Config(cfg Config) Builder {
+	b.config = &cfg
+	return b
+}
+
+// Details returns a copy of the preset details of the Builder, including the component name.
+func (b Builder) Details() ErrorDetails {
+	return b.errorDetails(nil)
+}
+
+// New returns an error with the given message and preset details.
+func (b Builder) New(message string, details ...any) error {
+	return b.newError(nil, message, nil, details)
+}
+
+// Wrap returns a new error that has the given message, preset details and err as the cause.
+// Returns nil when nil is passed.
+func (b Builder) Wrap(err error, message string, details ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	return b.newError(nil, message, err, details)
+}
+
+// WithStack binds the given error to the current execution location and adds preset details.
+// Returns nil when nil is passed.
+func (b Builder) WithStack(err error, details ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	return b.newError(err, "", nil, details)
+}
+
+// WithCause adds a cause and preset details to the given error.
+// Returns nil when nil is passed.
+func (b Builder) WithCause(err, cause error, details ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	return b.newError(err, "", cause, details)
+}
+
+func (b Builder) newError(err error, message string, cause error, details []any) *errkitError {
+	cfg := DefaultConfig()
+	if b.config != nil {
+		cfg = *b.config
+	}
+
+	e := newErrorWithConfig(cfg, err, message, cause, 3, b.errorDetails(ToErrorDetails(details)))
+	e.severity = b.severity
+	return e
+}
+
+func (b Builder) errorDetails(details ErrorDetails) ErrorDetails {
+	result := mergeDetails(nil, b.details)
+	if b.component != "" {
+		result = mergeDetails(result, ErrorDetails{componentKey: b.component})
+	}
+	return mergeDetails(result, details)
+}
+
+// mergeDetails returns a new ErrorDetails containing details from both base and override.
+// Values from override take precedence. Returns nil when both are empty.
+func mergeDetails(base, override ErrorDetails) ErrorDetails {
+	if len(base)+len(override) == 0 {
+		return nil
+	}
+
+	result := make(ErrorDetails, len(base)+len(override))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range override {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package errkit_test
+
+import (
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Run("It should add preset details to every created error", func(t *testing.T) {
+		b := errkit.With("namespace", "kanister", "actionset", "backup-1")
+
+		checkErrorResult(t, b.New("Some error", "phase", "copy"),
+			getMessageCheck("Some error"),
+			getDetailsCheck(errkit.ErrorDetails{"namespace": "kanister", "actionset": "backup-1", "phase": "copy"}),
+		)
+
+		checkErrorResult(t, b.Wrap(errPredefinedSentinelError, "Wrapped error"),
+			getMessageCheck("Wrapped error"),
+			getErrkitIsCheck(errPredefinedSentinelError),
+			getUnwrapCheck(errPredefinedSentinelError),
+			getDetailsCheck(errkit.ErrorDetails{"namespace": "kanister", "actionset": "backup-1"}),
+		)
+
+		cause := errkit.New("Cause")
+		checkErrorResult(t, b.WithCause(errPredefinedSentinelError, cause),
+			getMessageCheck("TEST_ERR: Sample of sentinel error"),
+			getErrkitIsCheck(errPredefinedSentinelError),
+			getUnwrapCheck(cause),
+		)
+	})
+
+	t.Run("It should capture the location where an error is created", func(t *testing.T) {
+		b := errkit.With("key", "value")
+		fnName, lineNumber := getStackInfo()
+		err := b.WithStack(errPredefinedTestError)
+		checkErrorResult(t, err,
+			getLocationCheck(fnName, lineNumber+1),
+			getDetailsCheck(errkit.ErrorDetails{"key": "value"}),
+		)
+	})
+
+	t.Run("It should let per-call details override preset details and component", func(t *testing.T) {
+		c := qt.New(t)
+		b := errkit.With("key", "preset", "other", 1).Component("controller")
+		c.Assert(b.Details(), qt.DeepEquals, errkit.ErrorDetails{"key": "preset", "other": 1, "component": "controller"})
+
+		checkErrorResult(t, b.New("Some error", "key", "call", "component", "override"),
+			getDetailsCheck(errkit.ErrorDetails{"key": "call", "other": float64(1), "component": "override"}),
+		)
+	})
+
+	t.Run("It should not modify the original builder", func(t *testing.T) {
+		c := qt.New(t)
+		base := errkit.With("key", "value")
+		_ = base.With("key", "changed", "extra", 1).Component("component")
+		c.Assert(base.Details(), qt.DeepEquals, errkit.ErrorDetails{"key": "value"})
+	})
+
+	t.Run("It should return nil when nil is passed", func(t *testing.T) {
+		c := qt.New(t)
+		b := errkit.With("key", "value")
+		c.Assert(b.Wrap(nil, "message"), qt.IsNil)
+		c.Assert(b.WithStack(nil), qt.IsNil)
+		c.Assert(b.WithCause(nil, errPredefinedStdError), qt.IsNil)
+	})
+
+	t.Run("It should use configuration overrides", func(t *testing.T) {
+		c := qt.New(t)
+		helper := func(b errkit.Builder) error {
+			return b.New("Created by helper")
+		}
+
+		b := errkit.Builder{}.Config(errkit.Config{StackDepth: 2, CallerSkip: 1})
+		fnName, lineNumber := getStackInfo()
+		err := helper(b)
+		checkErrorResult(t, err, getLocationCheck(fnName, lineNumber+1))
+
+		var st interface{ StackTrace() errkit.StackTrace }
+		c.Assert(errkit.As(err, &st), qt.IsTrue)
+		c.Assert(st.StackTrace(), qt.HasLen, 2)
+	})
+
+	t.Run("It should be safe to share a builder between goroutines", func(t *testing.T) {
+		b := errkit.With("shared", true)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_ = b.With("id", i).New("Concurrent error")
+			}(i)
+		}
+		wg.Wait()
+	})
+}
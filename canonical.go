@@ -0,0 +1,77 @@
+package errkit
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// CanonicalOptions controls the output of MarshalCanonicalJSON.
+type CanonicalOptions struct {
+	// OmitLocation removes function, file and line number of every error,
+	// so the output does not depend on where errors were created.
+	OmitLocation bool
+}
+
+var locationFields = []string{"function", "file", "linenumber"}
+
+// MarshalCanonicalJSON returns a canonical JSON representation of err, suitable for hashing
+// and comparison: keys of all objects are sorted, there is no insignificant whitespace,
+// HTML characters are not escaped and numbers are written exactly as they were serialized.
+func MarshalCanonicalJSON(err error, opts CanonicalOptions) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+
+	raw, e := json.Marshal(jsonMarshable(err))
+	if e != nil {
+		return nil, e
+	}
+
+	// Round trip through generic values, which are always encoded with sorted keys
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value any
+	if e := dec.Decode(&value); e != nil {
+		return nil, e
+	}
+
+	if opts.OmitLocation {
+		omitLocation(value)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if e := enc.Encode(value); e != nil {
+		return nil, e
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// omitLocation removes location fields of an error node, which is an object having "message" field,
+// and of errors nested in its cause and members. Details and payloads are left intact, even if they look like errors.
+func omitLocation(value any) {
+	v, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	if _, ok := v["message"]; !ok {
+		return
+	}
+
+	for _, f := range locationFields {
+		delete(v, f)
+	}
+	omitLocation(v["cause"])
+	switch errs := v["errors"].(type) {
+	case []any:
+		for _, nested := range errs {
+			omitLocation(nested)
+		}
+	case map[string]any:
+		for _, nested := range errs {
+			omitLocation(nested)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package errkit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func newErrorWithManyDetails() error {
+	details := errkit.ErrorDetails{}
+	for i := 0; i < 20; i++ {
+		details[fmt.Sprintf("key_%02d", 19-i)] = i
+	}
+	inner := errkit.New("Inner error", details)
+	return errkit.Wrap(errkit.Append(inner, errkit.New("Other error", details)), "Outer error <html>", details)
+}
+
+func TestDeterministicOutput(t *testing.T) {
+	t.Run("It should produce the same output on every invocation", func(t *testing.T) {
+		c := qt.New(t)
+		err := newErrorWithManyDetails()
+
+		outputs := func() []string {
+			jsonData, e := json.Marshal(err)
+			c.Assert(e, qt.IsNil)
+
+			var rendered, logged bytes.Buffer
+			c.Assert(errkit.Render(&rendered, err, errkit.RenderOptions{}), qt.IsNil)
+			slog.New(slog.NewTextHandler(&logged, &slog.HandlerOptions{
+				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return a
+				},
+			})).Error("failed", "error", err)
+
+			return []string{string(jsonData), rendered.String(), fmt.Sprintf("%+v", err), logged.String()}
+		}
+
+		expected := outputs()
+		for i := 0; i < 20; i++ {
+			c.Assert(outputs(), qt.DeepEquals, expected)
+		}
+	})
+
+	t.Run("It should log details sorted by name with slog", func(t *testing.T) {
+		c := qt.New(t)
+		var logged bytes.Buffer
+		err := errkit.New("Some error", "b", 2, "a", 1, "c", errkit.New("Detail error"))
+		slog.New(slog.NewJSONHandler(&logged, nil)).Error("failed", "error", err)
+
+		out := logged.String()
+		c.Assert(out, qt.Contains, `"message":"Some error"`)
+		c.Assert(out, qt.Contains, `"details":{"a":1,"b":2,"c":{"message":"Detail error"`)
+		c.Assert(out, qt.Contains, `"function":"github.com/kanisterio/errkit_test.TestDeterministicOutput.func2"`)
+	})
+
+	t.Run("It should produce canonical JSON", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(errkit.New("Inner error", "z", 1.50, "a", "<b>"), "Outer error")
+		data, e := errkit.MarshalCanonicalJSON(err, errkit.CanonicalOptions{OmitLocation: true})
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(data), qt.Equals, `{"cause":{"details":{"a":"<b>","z":1.5},"message":"Inner error"},"message":"Outer error","schema_version":1}`)
+
+		withLocation, e := errkit.MarshalCanonicalJSON(err, errkit.CanonicalOptions{})
+		c.Assert(e, qt.IsNil)
+		c.Assert(strings.Contains(string(withLocation), `"function":"github.com/kanisterio/errkit_test.TestDeterministicOutput.func3"`), qt.IsTrue)
+
+		// Errors created at different places have the same canonical representation without location
+		other, e := errkit.MarshalCanonicalJSON(errkit.Wrap(errkit.New("Inner error", "a", "<b>", "z", 1.5), "Outer error"), errkit.CanonicalOptions{OmitLocation: true})
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(other), qt.Equals, string(data))
+	})
+
+	t.Run("It should omit location only of errors and keep details and payloads", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Append(
+			errkit.New("x", "file", "/etc/a", "message", "m"),
+			errkit.NewTyped("y", struct {
+				Message string `json:"message"`
+				File    string `json:"file"`
+			}{Message: "m", File: "/etc/b"}),
+		)
+		data, e := errkit.MarshalCanonicalJSON(err, errkit.CanonicalOptions{OmitLocation: true})
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(data), qt.Equals, `{"errors":[{"details":{"file":"/etc/a","message":"m"},"message":"x"},{"message":"y","payload":{"file":"/etc/b","message":"m"}}],"message":"2 errors have occurred","schema_version":1}`)
+	})
+}
@@ -0,0 +1,269 @@
+// Command errkit finds errkit errors in JSON or NDJSON logs and prints them as readable trees.
+//
+// Usage:
+//
+//	errkit [flags] [file ...]
+//
+// When no files are given, the standard input is read.
+// Errors are found at any level of the log entries, including ones encoded as JSON strings.
+//
+// Flags:
+//
+//	--filter field=value  print only errors matching the filter, can be repeated.
+//	                      Supported fields: message (substring of any message in the chain),
+//	                      code (value of the "code" detail, a shorthand for detail.code)
+//	                      and detail.<name> (value of a detail).
+//	--stats               print the number of errors grouped by fingerprint instead of errors.
+//	--max-depth N         maximum depth of rendered trees.
+//	--no-location         hide function, file and line of errors.
+//	--color mode          use ANSI colors: auto, always or never.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kanisterio/errkit"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type filters []filter
+
+func (f *filters) String() string {
+	return fmt.Sprint(*f)
+}
+
+func (f *filters) Set(value string) error {
+	parsed, err := parseFilter(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, parsed)
+	return nil
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("errkit", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var (
+		filterList filters
+		stats      bool
+		maxDepth   int
+		noLocation bool
+		color      string
+	)
+	flags.Var(&filterList, "filter", "print only errors matching `field=value`, where field is message, code or detail.<name>")
+	flags.BoolVar(&stats, "stats", false, "print the number of errors grouped by fingerprint")
+	flags.IntVar(&maxDepth, "max-depth", 0, "maximum depth of rendered trees, 0 means unlimited")
+	flags.BoolVar(&noLocation, "no-location", false, "hide function, file and line of errors")
+	flags.StringVar(&color, "color", "auto", "use ANSI colors: auto, always or never")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	opts := errkit.RenderOptions{MaxDepth: maxDepth, HideLocation: noLocation}
+	switch color {
+	case "auto":
+		opts.Color = errkit.ColorAuto
+	case "always":
+		opts.Color = errkit.ColorAlways
+	case "never":
+		opts.Color = errkit.ColorNever
+	default:
+		fmt.Fprintf(stderr, "invalid value %q for --color\n", color)
+		return 2
+	}
+
+	found, err := readAll(flags.Args(), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	var matched []error
+	for _, e := range found {
+		if filterList.match(e) {
+			matched = append(matched, e)
+		}
+	}
+
+	if stats {
+		if err := printStats(stdout, matched); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	for i, e := range matched {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		if err := errkit.Render(stdout, e, opts); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+func readAll(files []string, stdin io.Reader) ([]error, error) {
+	if len(files) == 0 {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read standard input: %w", err)
+		}
+		return scan(data), nil
+	}
+
+	var result []error
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, scan(data)...)
+	}
+	return result, nil
+}
+
+type filter struct {
+	field string
+	value string
+}
+
+func parseFilter(value string) (filter, error) {
+	field, v, ok := strings.Cut(value, "=")
+	if !ok {
+		return filter{}, errors.New("filter should be in the form field=value")
+	}
+
+	if field != "message" && field != "code" && !strings.HasPrefix(field, "detail.") {
+		return filter{}, fmt.Errorf("unsupported filter field %q, expected message, code or detail.<name>", field)
+	}
+	if field == "code" {
+		// The code of an error is its "code" detail
+		field = "detail.code"
+	}
+	return filter{field: field, value: v}, nil
+}
+
+// match returns true if err matches all filters.
+func (f filters) match(err error) bool {
+	for _, flt := range f {
+		if !flt.match(err) {
+			return false
+		}
+	}
+	return true
+}
+
+// match returns true if any error in the tree matches the filter.
+func (f filter) match(err error) bool {
+	matched := false
+	errkit.Walk(err, func(err error, _ int, _ []int) errkit.WalkAction {
+		switch err.(type) {
+		case errkit.ErrorList, errkit.ErrorMap:
+			// Members are matched on their own
+			return errkit.WalkContinue
+		}
+
+		switch {
+		case f.field == "message":
+			matched = strings.Contains(errkit.MessageOf(err), f.value)
+		case strings.HasPrefix(f.field, "detail."):
+			v, ok := errkit.DetailsOf(err)[strings.TrimPrefix(f.field, "detail.")]
+			matched = ok && fmt.Sprint(v) == f.value
+		}
+
+		if matched {
+			return errkit.WalkStop
+		}
+		return errkit.WalkContinue
+	})
+	return matched
+}
+
+// fingerprint identifies errors having the same structure, messages and functions,
+// regardless of details and line numbers.
+func fingerprint(err error) string {
+	h := sha256.New()
+	var write func(err error)
+	write = func(err error) {
+		switch e := err.(type) {
+		case errkit.ErrorList:
+			_, _ = io.WriteString(h, "[")
+			for _, member := range e {
+				write(member)
+			}
+			_, _ = io.WriteString(h, "]")
+		case errkit.ErrorMap:
+			// Keys identify items, the same as details do, so only members are taken into account
+			_, _ = io.WriteString(h, "{")
+			for _, member := range e.Unwrap() {
+				write(member)
+			}
+			_, _ = io.WriteString(h, "}")
+		default:
+			var function string
+			if l, ok := err.(errkit.Located); ok {
+				function, _, _ = l.Location()
+			}
+			_, _ = io.WriteString(h, errkit.MessageOf(err)+"\x00"+function+"\x00")
+			if cause := errors.Unwrap(err); cause != nil {
+				write(cause)
+			}
+		}
+	}
+	write(err)
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+func printStats(w io.Writer, errs []error) error {
+	type group struct {
+		fingerprint string
+		message     string
+		count       int
+	}
+
+	groups := map[string]*group{}
+	for _, e := range errs {
+		fp := fingerprint(e)
+		g, ok := groups[fp]
+		if !ok {
+			g = &group{fingerprint: fp, message: errkit.MessageOf(e)}
+			groups[fp] = g
+		}
+		g.count++
+	}
+
+	sorted := make([]*group, 0, len(groups))
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].fingerprint < sorted[j].fingerprint
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "COUNT\tFINGERPRINT\tMESSAGE")
+	for _, g := range sorted {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", g.count, g.fingerprint, g.message)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func logLine(t *testing.T, msg string, err error) string {
+	t.Helper()
+	data, e := json.Marshal(map[string]any{"level": "error", "msg": msg, "error": err})
+	if e != nil {
+		t.Fatalf("unable to marshal log line: %s", e.Error())
+	}
+	return string(data)
+}
+
+func runCLI(t *testing.T, input string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(input), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestCLI(t *testing.T) {
+	errNotFound := errkit.NewSentinelErr("Not found")
+	restoreErr := errkit.Wrap(errkit.WithCause(errNotFound, errkit.New("PVC is missing", "pvc", "data-0")), "Unable to restore", "namespace", "kanister")
+	listErr := errkit.Append(errkit.New("First failure", "code", "E1"), errkit.New("Second failure"))
+
+	input := strings.Join([]string{
+		logLine(t, "restore failed", restoreErr),
+		"not a json line",
+		logLine(t, "batch failed", listErr),
+		// Errors encoded as a JSON string inside a log field
+		`{"level":"error","error":` + string(mustMarshal(t, string(mustMarshal(t, restoreErr)))) + `}`,
+	}, "\n")
+
+	t.Run("It should print errors found in logs as trees", func(t *testing.T) {
+		c := qt.New(t)
+		stdout, stderr, code := runCLI(t, input, "--no-location", "--filter", "message=restore")
+		c.Assert(code, qt.Equals, 0, qt.Commentf("stderr: %s", stderr))
+
+		tree := strings.Join([]string{
+			"Unable to restore",
+			"│  namespace=kanister",
+			"└─ Not found",
+			"   └─ PVC is missing",
+			"         pvc=data-0",
+			"",
+		}, "\n")
+		c.Assert(stdout, qt.Equals, tree+"\n"+tree)
+	})
+
+	t.Run("It should print error lists as branches", func(t *testing.T) {
+		c := qt.New(t)
+		stdout, _, code := runCLI(t, input, "--no-location", "--filter", "code=E1")
+		c.Assert(code, qt.Equals, 0)
+		c.Assert(stdout, qt.Equals, "2 errors have occurred\n├─ First failure\n│     code=E1\n└─ Second failure\n")
+	})
+
+	t.Run("It should print error maps as branches ordered by key", func(t *testing.T) {
+		c := qt.New(t)
+		mapErr := errkit.ErrorMap{"pvc-b": errkit.New("Second failure"), "pvc-a": errkit.New("First failure")}
+		stdout, _, code := runCLI(t, logLine(t, "phase failed", mapErr), "--no-location")
+		c.Assert(code, qt.Equals, 0)
+		c.Assert(stdout, qt.Equals, "2 errors have occurred\n├─ pvc-a: First failure\n└─ pvc-b: Second failure\n")
+
+		stdout, _, code = runCLI(t, logLine(t, "phase failed", mapErr), "--no-location", "--filter", "message=Second")
+		c.Assert(code, qt.Equals, 0)
+		c.Assert(stdout, qt.Contains, "pvc-b: Second failure")
+	})
+
+	t.Run("It should filter errors by detail", func(t *testing.T) {
+		c := qt.New(t)
+		stdout, _, code := runCLI(t, input, "--filter", "detail.pvc=data-0", "--filter", "detail.namespace=kanister")
+		c.Assert(code, qt.Equals, 0)
+		c.Assert(strings.Count(stdout, "Unable to restore"), qt.Equals, 2)
+		c.Assert(stdout, qt.Contains, "at github.com/kanisterio/errkit/cmd/errkit.TestCLI")
+	})
+
+	t.Run("It should count errors by fingerprint", func(t *testing.T) {
+		c := qt.New(t)
+		stdout, _, code := runCLI(t, input, "--stats")
+		c.Assert(code, qt.Equals, 0)
+
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		c.Assert(lines, qt.HasLen, 3)
+		c.Assert(lines[0], qt.Matches, `COUNT\s+FINGERPRINT\s+MESSAGE`)
+		c.Assert(lines[1], qt.Matches, `2\s+[0-9a-f]{12}\s+Unable to restore`)
+	})
+
+	t.Run("It should reject unsupported filters", func(t *testing.T) {
+		c := qt.New(t)
+		_, stderr, code := runCLI(t, input, "--filter", "level=error")
+		c.Assert(code, qt.Equals, 2)
+		c.Assert(stderr, qt.Contains, "unsupported filter field")
+	})
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("unable to marshal: %s", err.Error())
+	}
+	return data
+}
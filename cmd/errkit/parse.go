@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/kanisterio/errkit"
+)
+
+// errkitFields are the fields which, along with "message", identify an errkit error object.
+var errkitFields = []string{"schema_version", "function", "file", "linenumber", "details", "cause", "errors"}
+
+// scan decodes every JSON value found in data, skipping lines which are not JSON,
+// and returns errkit errors embedded in them.
+func scan(data []byte) []error {
+	var result []error
+	for len(data) > 0 {
+		data = bytes.TrimLeft(data, " \t\r\n")
+		if len(data) == 0 {
+			break
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			// Not a JSON value, skipping the rest of the line
+			next := bytes.IndexByte(data, '\n')
+			if next < 0 {
+				break
+			}
+			data = data[next+1:]
+			continue
+		}
+
+		result = append(result, find(value)...)
+		data = data[dec.InputOffset():]
+	}
+	return result
+}
+
+// find looks for errkit error objects in a decoded JSON value,
+// including ones nested inside other objects or encoded as JSON strings.
+func find(value any) []error {
+	switch v := value.(type) {
+	case map[string]any:
+		if isErrkitError(v) {
+			return []error{decode(v)}
+		}
+
+		keys := sortedKeys(v)
+		var result []error
+		for _, k := range keys {
+			result = append(result, find(v[k])...)
+		}
+		return result
+	case []any:
+		var result []error
+		for _, item := range v {
+			result = append(result, find(item)...)
+		}
+		return result
+	case string:
+		s := strings.TrimSpace(v)
+		if !strings.HasPrefix(s, "{") {
+			return nil
+		}
+
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		var nested any
+		if dec.Decode(&nested) != nil {
+			return nil
+		}
+		return find(nested)
+	}
+	return nil
+}
+
+func isErrkitError(v map[string]any) bool {
+	if _, ok := v["message"].(string); !ok {
+		return false
+	}
+
+	for _, f := range errkitFields {
+		if _, ok := v[f]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// decode decodes an errkit error object with errkit.UnmarshalErrorFromJSON,
+// objects which could not be decoded are kept as their messages.
+func decode(v map[string]any) error {
+	data, err := json.Marshal(v)
+	if err == nil {
+		var decoded error
+		if decoded, err = errkit.UnmarshalErrorFromJSON(data); err == nil && decoded != nil {
+			return decoded
+		}
+	}
+
+	message, _ := v["message"].(string)
+	return plainError(message)
+}
+
+type plainError string
+
+func (e plainError) Error() string { return string(e) }
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,62 @@
+package errkit
+
+import "sync/atomic"
+
+// Config controls how errkit errors are created.
+// The zero value is ready to use and corresponds to the default behavior.
+type Config struct {
+	// StackDepth is the maximum number of frames captured for each error.
+	// When zero, 32 frames are captured.
+	StackDepth int
+	// CallerSkip is the number of additional frames to skip when capturing the stack.
+	// It is useful for helpers which create errors on behalf of their callers.
+	CallerSkip int
+	// MatchDetailErrors makes errkit.Is and errkit.As search through detail values
+	// which are errors, in addition to the chain of causes.
+	MatchDetailErrors bool
+	// SkipStackIfCaused disables capturing the stack for errors having a cause
+	// which already carries a stack, e.g. created by errkit or by pkg/errors.
+	// Such errors have no location of their own.
+	SkipStackIfCaused bool
+	// StackSampling makes only one of every StackSampling errors capture the stack,
+	// the rest have no location. Values below 2 make every error capture the stack.
+	StackSampling int
+}
+
+var defaultConfig atomic.Pointer[Config]
+
+// stackSamples counts errors created while stack sampling is enabled.
+var stackSamples atomic.Uint64
+
+// SetDefaultConfig replaces the configuration used by all errors created
+// without a Builder having its own configuration.
+func SetDefaultConfig(cfg Config) {
+	defaultConfig.Store(&cfg)
+}
+
+// DefaultConfig returns the configuration used by errors created
+// without a Builder having its own configuration.
+func DefaultConfig() Config {
+	if cfg := defaultConfig.Load(); cfg != nil {
+		return *cfg
+	}
+	return Config{}
+}
+
+func (c Config) stackDepth() int {
+	if c.StackDepth <= 0 {
+		return maxStackDepth
+	}
+	return c.StackDepth
+}
+
+// captureStack reports whether an error with the given cause has to capture the stack.
+func (c Config) captureStack(cause error) bool {
+	if c.SkipStackIfCaused && causeStack(cause) != nil {
+		return false
+	}
+	if c.StackSampling > 1 {
+		return (stackSamples.Add(1)-1)%uint64(c.StackSampling) == 0
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package errkit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func hasLocation(err error) bool {
+	function, _, _ := err.(interface {
+		Location() (function, file string, line int)
+	}).Location()
+	return function != ""
+}
+
+func TestStackCapture(t *testing.T) {
+	t.Run("It should wrap an error without capturing the stack", func(t *testing.T) {
+		c := qt.New(t)
+		cause := errkit.New("Root cause")
+		err := errkit.WrapNoStack(cause, "Wrapped error", "key", "value")
+
+		c.Assert(err.Error(), qt.Equals, "Wrapped error: Root cause")
+		c.Assert(errors.Is(err, cause), qt.IsTrue)
+		c.Assert(hasLocation(err), qt.IsFalse)
+		c.Assert(errkit.WrapNoStack(nil, "Wrapped error"), qt.IsNil)
+
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		var result map[string]any
+		c.Assert(json.Unmarshal(data, &result), qt.IsNil)
+		_, ok := result["function"]
+		c.Assert(ok, qt.IsFalse)
+		c.Assert(result["details"], qt.DeepEquals, map[string]any{"key": "value"})
+	})
+
+	t.Run("It should skip the stack when the cause already has one", func(t *testing.T) {
+		c := qt.New(t)
+		b := errkit.Builder{}.Config(errkit.Config{SkipStackIfCaused: true})
+
+		withStack := b.Wrap(b.Wrap(errkit.New("Root cause"), "Middle error"), "Top error")
+		c.Assert(hasLocation(withStack), qt.IsFalse)
+		c.Assert(hasLocation(errors.Unwrap(withStack)), qt.IsFalse)
+
+		fnName, lineNumber := getStackInfo()
+		withoutStack := b.Wrap(errors.New("Plain error"), "Top error")
+		checkErrorResult(t, withoutStack, getLocationCheck(fnName, lineNumber+1))
+	})
+
+	t.Run("It should capture the stack for a sample of errors", func(t *testing.T) {
+		c := qt.New(t)
+		b := errkit.Builder{}.Config(errkit.Config{StackSampling: 3})
+
+		sampled := 0
+		for i := 0; i < 9; i++ {
+			if hasLocation(b.New("Some error")) {
+				sampled++
+			}
+		}
+		c.Assert(sampled, qt.Equals, 3)
+	})
+}
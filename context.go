@@ -0,0 +1,124 @@
+package errkit
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type contextDetailsKey struct{}
+
+// ContextDetailsHook extracts details from a context.
+// It allows including values managed by other libraries, e.g. OpenTelemetry trace IDs,
+// without errkit depending on them.
+type ContextDetailsHook func(ctx context.Context) ErrorDetails
+
+var (
+	contextHooksMu sync.RWMutex
+	contextHooks   []ContextDetailsHook
+)
+
+// RegisterContextDetailsHook registers a hook which is called for every error
+// created with a context. Details returned by the hook are added to the error.
+//
+//	errkit.RegisterContextDetailsHook(func(ctx context.Context) errkit.ErrorDetails {
+//	    sc := trace.SpanContextFromContext(ctx)
+//	    if !sc.IsValid() {
+//	        return nil
+//	    }
+//	    return errkit.ErrorDetails{"trace_id": sc.TraceID().String()}
+//	})
+func RegisterContextDetailsHook(hook ContextDetailsHook) {
+	if hook == nil {
+		return
+	}
+
+	contextHooksMu.Lock()
+	defer contextHooksMu.Unlock()
+	contextHooks = append(contextHooks, hook)
+}
+
+// WithContextDetails returns a copy of ctx carrying the given details, in addition
+// to details already stored in ctx. Details are passed the same way as to New.
+func WithContextDetails(ctx context.Context, details ...any) context.Context {
+	existing, _ := ctx.Value(contextDetailsKey{}).(ErrorDetails)
+	return context.WithValue(ctx, contextDetailsKey{}, mergeDetails(existing, ToErrorDetails(details)))
+}
+
+// ContextDetails returns details which are added to errors created with ctx.
+// Details returned by registered hooks are overridden by details stored with WithContextDetails.
+func ContextDetails(ctx context.Context) ErrorDetails {
+	if ctx == nil {
+		return nil
+	}
+
+	contextHooksMu.RLock()
+	hooks := contextHooks
+	contextHooksMu.RUnlock()
+
+	var result ErrorDetails
+	for _, hook := range hooks {
+		result = mergeDetails(result, hook(ctx))
+	}
+
+	stored, _ := ctx.Value(contextDetailsKey{}).(ErrorDetails)
+	return mergeDetails(result, stored)
+}
+
+// NewCtx returns an error with the given message and details stored in ctx.
+// Details passed explicitly override details from ctx.
+func NewCtx(ctx context.Context, message string, details ...any) error {
+	return newErrorWithConfig(DefaultConfig(), nil, message, nil, 2, mergeDetails(ContextDetails(ctx), ToErrorDetails(details)))
+}
+
+// WrapCtx returns a new errkitError that has the given message, details stored in ctx
+// and err as the cause. Details passed explicitly override details from ctx.
+// Returns nil when nil is passed.
+func WrapCtx(ctx context.Context, err error, message string, details ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	return newErrorWithConfig(DefaultConfig(), nil, message, err, 2, mergeDetails(ContextDetails(ctx), ToErrorDetails(details)))
+}
+
+// CancelCauseFunc cancels a context created by WithCancelCause.
+// The stack of its caller and the given details are captured along with the cause.
+// When cause is nil, context.Canceled is used as the cause.
+type CancelCauseFunc func(cause error, details ...any)
+
+// WithCancelCause is the same as context.WithCancelCause, but the returned cancel function
+// binds the cause to the location where it was invoked, so context.Cause and ContextErr
+// return an error with a stack instead of a bare context.Canceled.
+func WithCancelCause(parent context.Context) (context.Context, CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	return ctx, func(cause error, details ...any) {
+		if cause == nil {
+			cause = context.Canceled
+		}
+		cancel(newError(cause, nil, 2, details...))
+	}
+}
+
+// ContextErr returns an error combining ctx.Err() with the cause of cancellation,
+// or nil if ctx is not done yet. Since ctx.Err() is kept as the error itself,
+// errkit.Is(err, context.Canceled) still holds, while the cause is available with Unwrap.
+// If the context has no cause other than ctx.Err(), the stack of the ContextErr caller is captured.
+func ContextErr(ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+
+	cause := context.Cause(ctx)
+	if cause == err {
+		return newError(err, nil, 2)
+	}
+
+	if _, ok := cause.(*errkitError); ok && errors.Is(cause, err) {
+		// Context was cancelled without a specific cause, which was already bound to a stack
+		return cause
+	}
+
+	return newError(err, cause, 2)
+}
@@ -0,0 +1,129 @@
+package errkit_test
+
+import (
+	"context"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+type traceIDKey struct{}
+
+func init() {
+	// Simulates a hook extracting trace IDs, e.g. from OpenTelemetry span context
+	errkit.RegisterContextDetailsHook(func(ctx context.Context) errkit.ErrorDetails {
+		traceID, ok := ctx.Value(traceIDKey{}).(string)
+		if !ok {
+			return nil
+		}
+		return errkit.ErrorDetails{"trace_id": traceID}
+	})
+}
+
+func TestContextDetails(t *testing.T) {
+	t.Run("It should be possible to create an error with details from context", func(t *testing.T) {
+		ctx := errkit.WithContextDetails(context.Background(), "request_id", "abc", "user", "admin")
+		ctx = errkit.WithContextDetails(ctx, "operation", "backup")
+
+		fnName, lineNumber := getStackInfo()
+		err := errkit.NewCtx(ctx, "Some error", "key", "value")
+		checkErrorResult(t, err,
+			getMessageCheck("Some error"),
+			getLocationCheck(fnName, lineNumber+1),
+			getDetailsCheck(errkit.ErrorDetails{"request_id": "abc", "user": "admin", "operation": "backup", "key": "value"}),
+		)
+	})
+
+	t.Run("It should be possible to wrap an error with details from context", func(t *testing.T) {
+		ctx := errkit.WithContextDetails(context.Background(), "request_id", "abc", "user", "admin")
+
+		fnName, lineNumber := getStackInfo()
+		err := errkit.WrapCtx(ctx, errPredefinedSentinelError, "Wrapped error", "user", "override")
+		checkErrorResult(t, err,
+			getMessageCheck("Wrapped error"),
+			getLocationCheck(fnName, lineNumber+1),
+			getErrkitIsCheck(errPredefinedSentinelError),
+			getUnwrapCheck(errPredefinedSentinelError),
+			getDetailsCheck(errkit.ErrorDetails{"request_id": "abc", "user": "override"}),
+		)
+	})
+
+	t.Run("It should add details returned by registered hooks", func(t *testing.T) {
+		c := qt.New(t)
+		ctx := context.WithValue(context.Background(), traceIDKey{}, "4bf92f3577b34da6")
+		ctx = errkit.WithContextDetails(ctx, "request_id", "abc")
+
+		c.Assert(errkit.ContextDetails(ctx), qt.DeepEquals, errkit.ErrorDetails{"request_id": "abc", "trace_id": "4bf92f3577b34da6"})
+		checkErrorResult(t, errkit.NewCtx(ctx, "Some error"),
+			getDetailsCheck(errkit.ErrorDetails{"request_id": "abc", "trace_id": "4bf92f3577b34da6"}),
+		)
+	})
+
+	t.Run("It should not modify details stored in parent context", func(t *testing.T) {
+		c := qt.New(t)
+		parent := errkit.WithContextDetails(context.Background(), "key", "parent")
+		_ = errkit.WithContextDetails(parent, "key", "child")
+		c.Assert(errkit.ContextDetails(parent), qt.DeepEquals, errkit.ErrorDetails{"key": "parent"})
+		c.Assert(errkit.ContextDetails(context.Background()), qt.IsNil)
+	})
+
+	t.Run("It should return nil when nil is passed", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(errkit.WrapCtx(context.Background(), nil, "Some message"), qt.IsNil)
+	})
+}
+
+func TestContextCancelCause(t *testing.T) {
+	errSomethingFailed := errkit.NewSentinelErr("Something failed")
+
+	t.Run("It should capture the stack of the cancel function caller", func(t *testing.T) {
+		c := qt.New(t)
+		ctx, cancel := errkit.WithCancelCause(context.Background())
+		fnName, lineNumber := getStackInfo()
+		cancel(errSomethingFailed, "key", "value")
+
+		cause := context.Cause(ctx)
+		c.Assert(errkit.Is(cause, errSomethingFailed), qt.IsTrue)
+		checkErrorResult(t, cause,
+			getLocationCheck(fnName, lineNumber+1),
+			getDetailsCheck(errkit.ErrorDetails{"key": "value"}),
+		)
+
+		err := errkit.ContextErr(ctx)
+		c.Assert(errkit.Is(err, context.Canceled), qt.IsTrue)
+		c.Assert(errkit.Is(err, errSomethingFailed), qt.IsTrue)
+		c.Assert(errkit.Unwrap(err), qt.Equals, cause)
+		c.Assert(err.Error(), qt.Equals, "context canceled: Something failed")
+	})
+
+	t.Run("It should use context.Canceled when no cause is passed", func(t *testing.T) {
+		c := qt.New(t)
+		ctx, cancel := errkit.WithCancelCause(context.Background())
+		fnName, lineNumber := getStackInfo()
+		cancel(nil)
+
+		err := errkit.ContextErr(ctx)
+		c.Assert(errkit.Is(err, context.Canceled), qt.IsTrue)
+		c.Assert(err.Error(), qt.Equals, "context canceled")
+		checkErrorResult(t, err, getLocationCheck(fnName, lineNumber+1))
+	})
+
+	t.Run("It should capture the stack of ContextErr caller for contexts cancelled without a cause", func(t *testing.T) {
+		c := qt.New(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		fnName, lineNumber := getStackInfo()
+		err := errkit.ContextErr(ctx)
+		c.Assert(errkit.Is(err, context.Canceled), qt.IsTrue)
+		checkErrorResult(t, err, getLocationCheck(fnName, lineNumber+1))
+	})
+
+	t.Run("It should return nil when context is not done", func(t *testing.T) {
+		c := qt.New(t)
+		ctx, cancel := errkit.WithCancelCause(context.Background())
+		defer cancel(nil)
+		c.Assert(errkit.ContextErr(ctx), qt.IsNil)
+	})
+}
@@ -1,12 +1,17 @@
 package errkit
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 const (
-	noVal  = "NOVAL"
-	badKey = "BADKEY"
+	noVal    = "NOVAL"
+	badKey   = "BADKEY"
+	badValue = "BADVALUE"
 )
 
 type ErrorDetails map[string]any
@@ -48,3 +53,169 @@ func ToErrorDetails(details []any) ErrorDetails {
 
 	return errorDetails
 }
+
+// marshalDetails serializes each detail on its own, so a single value which can not
+// be serialized (a channel, a function, a cyclic structure or a value with failing MarshalJSON)
+// does not prevent the whole error from being serialized. Such values are replaced with
+// their fmt representation marked with BADVALUE.
+// Details exceeding limits are omitted or truncated, in which case true is returned.
+func marshalDetails(details ErrorDetails, l Limits, depth int) (ErrorDetails, bool) {
+	if len(details) == 0 {
+		return nil, false
+	}
+
+	keys := details.sortedKeys()
+	truncatedDetails := len(keys) > l.maxDetails()
+	if truncatedDetails {
+		keys = keys[:l.maxDetails()]
+	}
+
+	result := make(ErrorDetails, len(keys))
+	for _, k := range keys {
+		value, truncated := marshalLimitedValue(details[k], l, depth)
+		result[k] = value
+		truncatedDetails = truncatedDetails || truncated
+	}
+	return result, truncatedDetails
+}
+
+// marshalLimitedValue serializes a value of a detail or a payload, replacing values which can not be serialized
+// and truncating values exceeding limits, in which case true is returned.
+func marshalLimitedValue(v any, l Limits, depth int) (any, bool) {
+	raw, err := marshalDetail(v, l, depth)
+	if err != nil {
+		return unserializableValue(v), false
+	}
+
+	if len(raw) > l.maxDetailValueLength() {
+		return truncatedValue(raw, l.maxDetailValueLength()), true
+	}
+	return json.RawMessage(raw), false
+}
+
+func marshalDetail(v any, l Limits, depth int) ([]byte, error) {
+	if err, ok := v.(error); ok && err != nil {
+		// Errors are serialized the same way as causes, instead of mostly empty objects
+		if raw, e := marshalNested(err, l, depth+1); e == nil {
+			return raw, nil
+		}
+	}
+
+	return marshalValue(v)
+}
+
+// marshalValue is json.Marshal which also recovers from panics in MarshalJSON implementations.
+func marshalValue(v any) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic during serialization: %v", r)
+		}
+	}()
+
+	return json.Marshal(v)
+}
+
+// errors returns detail values which are errors, sorted by detail name.
+func (d ErrorDetails) errors() []error {
+	var result []error
+	for _, k := range d.sortedKeys() {
+		if err, ok := d[k].(error); ok && err != nil {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+func (d ErrorDetails) sortedKeys() []string {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// detailString formats a detail value with fmt, guarding against cyclic values.
+func detailString(v any) string {
+	if hasCycle(reflect.ValueOf(v), map[visit]bool{}) {
+		return fmt.Sprintf("cyclic %T", v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// String returns details sorted by name, in the same format as fmt prints maps.
+func (d ErrorDetails) String() string {
+	keys := d.sortedKeys()
+
+	var sb strings.Builder
+	sb.WriteString("map[")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(k)
+		sb.WriteByte(':')
+		sb.WriteString(detailString(d[k]))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+func unserializableValue(v any) string {
+	if hasCycle(reflect.ValueOf(v), map[visit]bool{}) {
+		return fmt.Sprintf("%s:(cyclic %T)", badValue, v)
+	}
+	return fmt.Sprintf("%s:(%+v)", badValue, v)
+}
+
+type visit struct {
+	ptr  uintptr
+	len  int
+	kind reflect.Kind
+}
+
+// hasCycle checks whether a value references itself, which would make
+// printing it with fmt recurse infinitely.
+func hasCycle(v reflect.Value, path map[visit]bool) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+
+		key := visit{ptr: v.Pointer(), kind: v.Kind()}
+		if v.Kind() == reflect.Slice {
+			key.len = v.Len()
+		}
+		if path[key] {
+			return true
+		}
+		path[key] = true
+		defer delete(path, key)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return hasCycle(v.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if hasCycle(v.Field(i), path) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if hasCycle(iter.Key(), path) || hasCycle(iter.Value(), path) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if hasCycle(v.Index(i), path) {
+				return true
+			}
+		}
+	}
+	return false
+}
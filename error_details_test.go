@@ -1,6 +1,10 @@
 package errkit_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -49,3 +53,154 @@ func TestToErrorDetails(t *testing.T) {
 		})
 	}
 }
+
+type cyclicStruct struct {
+	Name string
+	Next *cyclicStruct
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("marshaling failed")
+}
+
+func (failingMarshaler) String() string {
+	return "failing marshaler"
+}
+
+type failingError struct{}
+
+func (failingError) Error() string { return "failing error" }
+
+func (failingError) MarshalJSON() ([]byte, error) {
+	panic("unexpected panic")
+}
+
+func TestUnserializableDetails(t *testing.T) {
+	cyclic := &cyclicStruct{Name: "first"}
+	cyclic.Next = &cyclicStruct{Name: "second", Next: cyclic}
+	cyclicMap := map[string]any{"key": "value"}
+	cyclicMap["self"] = cyclicMap
+
+	cases := []struct {
+		testName string
+		value    any
+		expected string
+	}{
+		{
+			testName: "Channel",
+			value:    make(chan int),
+			expected: "BADVALUE:(0x",
+		},
+		{
+			testName: "Function",
+			value:    func() {},
+			expected: "BADVALUE:(0x",
+		},
+		{
+			testName: "Cyclic struct",
+			value:    cyclic,
+			expected: "BADVALUE:(cyclic *errkit_test.cyclicStruct)",
+		},
+		{
+			testName: "Cyclic map",
+			value:    cyclicMap,
+			expected: "BADVALUE:(cyclic map[string]interface {})",
+		},
+		{
+			testName: "Failing MarshalJSON",
+			value:    failingMarshaler{},
+			expected: "BADVALUE:(failing marshaler)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			c := qt.New(t)
+			err := errkit.Wrap(errkit.New("Cause", "valid", 1), "Some error", "invalid", tc.value, "valid", "value")
+			data, e := json.Marshal(err)
+			c.Assert(e, qt.IsNil)
+
+			var parsed struct {
+				Details errkit.ErrorDetails `json:"details"`
+				Cause   struct {
+					Message string              `json:"message"`
+					Details errkit.ErrorDetails `json:"details"`
+				} `json:"cause"`
+			}
+			c.Assert(json.Unmarshal(data, &parsed), qt.IsNil)
+			c.Assert(parsed.Details["valid"], qt.Equals, "value")
+			c.Assert(parsed.Details["invalid"], qt.Matches, regexp.QuoteMeta(tc.expected)+".*")
+			c.Assert(parsed.Cause.Message, qt.Equals, "Cause")
+			c.Assert(parsed.Cause.Details, qt.DeepEquals, errkit.ErrorDetails{"valid": float64(1)})
+
+			c.Assert(fmt.Sprintf("%+v", err), qt.Contains, "Some error map[invalid:")
+		})
+	}
+
+	t.Run("It should write causes which fail to serialize themselves", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(failingError{}, "Some error")
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+
+		var parsed struct {
+			Cause struct {
+				Message string `json:"message"`
+			} `json:"cause"`
+		}
+		c.Assert(json.Unmarshal(data, &parsed), qt.IsNil)
+		c.Assert(parsed.Cause.Message, qt.Equals, "failing error")
+
+		list, e := json.Marshal(errkit.Append(failingError{}, errkit.New("Other error")))
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(list), qt.Contains, `{"message":"failing error"}`)
+	})
+}
+
+func TestErrorDetailValues(t *testing.T) {
+	t.Run("It should serialize detail values which are errors", func(t *testing.T) {
+		c := qt.New(t)
+		original := errkit.Wrap(errors.New("disk is full"), "Unable to write file")
+		err := errkit.New("Cleanup failed", "original", original, "std", errors.New("std error"))
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+
+		var parsed struct {
+			Details struct {
+				Original struct {
+					Message  string `json:"message"`
+					Function string `json:"function"`
+					Cause    struct {
+						Message string `json:"message"`
+					} `json:"cause"`
+				} `json:"original"`
+				Std struct {
+					Message string `json:"message"`
+				} `json:"std"`
+			} `json:"details"`
+		}
+		c.Assert(json.Unmarshal(data, &parsed), qt.IsNil)
+		c.Assert(parsed.Details.Original.Message, qt.Equals, "Unable to write file")
+		c.Assert(parsed.Details.Original.Function, qt.Equals, "github.com/kanisterio/errkit_test.TestErrorDetailValues.func1")
+		c.Assert(parsed.Details.Original.Cause.Message, qt.Equals, "disk is full")
+		c.Assert(parsed.Details.Std.Message, qt.Equals, "std error")
+	})
+
+	t.Run("It should match detail values which are errors only when enabled", func(t *testing.T) {
+		c := qt.New(t)
+		original := errkit.WithStack(errPredefinedTestError)
+
+		err := errkit.New("Cleanup failed", "original", original)
+		c.Assert(errkit.Is(err, errPredefinedTestError), qt.IsFalse)
+
+		err = errkit.Builder{}.Config(errkit.Config{MatchDetailErrors: true}).New("Cleanup failed", "original", original)
+		c.Assert(errkit.Is(err, errPredefinedTestError), qt.IsTrue)
+		c.Assert(errkit.Is(err, errPredefinedStdError), qt.IsFalse)
+
+		var asErr *testErrorType
+		c.Assert(errkit.As(err, &asErr), qt.IsTrue)
+		c.Assert(asErr, qt.Equals, errPredefinedTestError)
+	})
+}
@@ -1,29 +1,49 @@
 package errkit
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 type ErrorList []error
 
 var _ error = (ErrorList)(nil)
 var _ json.Marshaler = (ErrorList)(nil)
+var _ json.Unmarshaler = (*ErrorList)(nil)
 
 func (e ErrorList) String() string {
-	sep := ""
-	var buf bytes.Buffer
-	buf.WriteRune('[')
-	for _, err := range e {
-		buf.WriteString(sep)
-		sep = ","
-		buf.WriteString(strconv.Quote(err.Error()))
+	l := DefaultLimits()
+	var sb strings.Builder
+	writeErrorText(&sb, e, l, 0)
+	return truncateText(sb.String(), l.maxBytes())
+}
+
+// writeText writes texts of members as a JSON array.
+func (e ErrorList) writeText(sb *strings.Builder, w textWriter, depth int) {
+	l := w.limits
+	var member strings.Builder
+	sb.WriteRune('[')
+	for i, err := range e {
+		if i > 0 {
+			sb.WriteRune(',')
+		}
+		if i == l.maxListLength() {
+			sb.WriteString(strconv.Quote(fmt.Sprintf("…(%d more errors truncated)", len(e)-i)))
+			break
+		}
+		if sb.Len() > l.maxBytes() {
+			// The result is going to be truncated anyway
+			break
+		}
+
+		member.Reset()
+		w.write(&member, err, depth+1)
+		sb.WriteString(strconv.Quote(member.String()))
 	}
-	buf.WriteRune(']')
-	return buf.String()
+	sb.WriteRune(']')
 }
 
 func (e ErrorList) Error() string {
@@ -50,26 +70,52 @@ func (e ErrorList) Is(target error) bool {
 	return false
 }
 
+type jsonErrorList struct {
+	SchemaVersion int               `json:"schema_version,omitempty" description:"Version of the JSON schema, present only on the top level object. Absent in payloads produced before versioning was introduced."`
+	Message       string            `json:"message" description:"Summary of the list, e.g. \"2 errors have occurred\"."`
+	Errors        []json.RawMessage `json:"errors" description:"Errors of the list. Usually errors or nested error lists, but errors implementing json.Marshaler could produce any JSON value."`
+	Truncated     bool              `json:"truncated,omitempty" description:"Set when some errors of the list were omitted because of size limits."`
+}
+
 func (e ErrorList) MarshalJSON() ([]byte, error) {
-	var je struct {
-		Message string            `json:"message"`
-		Errors  []json.RawMessage `json:"errors"`
+	l := DefaultLimits()
+	raw, err := e.marshalJSON(l, SchemaVersion, 0)
+	if err != nil || len(raw) <= l.maxBytes() {
+		return raw, err
 	}
 
-	switch len(e) {
-	case 0:
+	return json.Marshal(jsonErrorList{
+		SchemaVersion: SchemaVersion,
+		Message:       listMessage(len(e)),
+		Errors:        []json.RawMessage{},
+		Truncated:     true,
+	})
+}
+
+// marshalJSON serializes the list nested at the given depth, version is set only on the top level object.
+func (e ErrorList) marshalJSON(l Limits, version, depth int) ([]byte, error) {
+	return e.marshalList(listMessage(len(e)), l, version, depth)
+}
+
+// marshalList serializes the list with the given summary message.
+func (e ErrorList) marshalList(message string, l Limits, version, depth int) ([]byte, error) {
+	if len(e) == 0 {
 		// no errors
 		return []byte("null"), nil
-	case 1:
-		// this is unlikely to happen as kerrors.Append won't allow having just a single error on the list
-		je.Message = "1 error has occurred"
-	default:
-		je.Message = fmt.Sprintf("%d errors have occurred", len(e))
 	}
 
-	je.Errors = make([]json.RawMessage, 0, len(e))
+	je := jsonErrorList{
+		SchemaVersion: version,
+		Message:       message,
+		Errors:        make([]json.RawMessage, 0, min(len(e), l.maxListLength())),
+	}
 	for i := range e {
-		raw, err := json.Marshal(jsonMarshable(e[i]))
+		if i == l.maxListLength() {
+			je.Truncated = true
+			break
+		}
+
+		raw, err := marshalNested(e[i], l, depth+1)
 		if err != nil {
 			return nil, err
 		}
@@ -104,3 +150,110 @@ func Append(err1, err2 error) error {
 	}
 	return ErrorList{err1, err2}
 }
+
+// Err returns nil for an empty list, the only member for a list of one error, and the list itself otherwise.
+// It avoids returning an empty ErrorList as a non-nil error.
+func (e ErrorList) Err() error {
+	switch len(e) {
+	case 0:
+		return nil
+	case 1:
+		return e[0]
+	}
+	return e
+}
+
+// Filter returns a new list of members for which keep returns true, or nil if there are none.
+func (e ErrorList) Filter(keep func(error) bool) ErrorList {
+	var result ErrorList
+	for _, err := range e {
+		if keep(err) {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// Partition splits members into those for which match returns true and the rest.
+// Each of the results is nil if it has no members.
+func (e ErrorList) Partition(match func(error) bool) (matched, rest ErrorList) {
+	for _, err := range e {
+		if match(err) {
+			matched = append(matched, err)
+		} else {
+			rest = append(rest, err)
+		}
+	}
+	return matched, rest
+}
+
+// Map returns a new list of members transformed with fn, members for which fn returns nil are dropped.
+func (e ErrorList) Map(fn func(error) error) ErrorList {
+	var result ErrorList
+	for _, err := range e {
+		if mapped := fn(err); mapped != nil {
+			result = append(result, mapped)
+		}
+	}
+	return result
+}
+
+// GroupBy splits members into lists by the key returned by fn, keeping the order of members within each group.
+// The result is an ErrorMap having an ErrorList for each key, or nil if the list is empty.
+func (e ErrorList) GroupBy(fn func(error) string) ErrorMap {
+	if len(e) == 0 {
+		return nil
+	}
+
+	groups := map[string]ErrorList{}
+	for _, err := range e {
+		key := fn(err)
+		groups[key] = append(groups[key], err)
+	}
+
+	result := make(ErrorMap, len(groups))
+	for key, group := range groups {
+		result[key] = group
+	}
+	return result
+}
+
+// Without returns a new list of members which do not match target, as reported by errors.Is, or nil if there are none.
+func (e ErrorList) Without(target error) ErrorList {
+	return e.Filter(func(err error) bool {
+		return !errors.Is(err, target)
+	})
+}
+
+// UnmarshalJSON decodes the representation produced by MarshalJSON.
+// Members are decoded as errors keeping their messages, locations, details and causes,
+// nested lists are decoded as ErrorList.
+func (e *ErrorList) UnmarshalJSON(data []byte) error {
+	var je jsonErrorList
+	if err := json.Unmarshal(data, &je); err != nil {
+		return err
+	}
+
+	if err := checkSchemaVersion(je.SchemaVersion); err != nil {
+		return err
+	}
+
+	if je.Errors == nil {
+		*e = nil
+		return nil
+	}
+
+	list := make(ErrorList, 0, len(je.Errors))
+	for _, raw := range je.Errors {
+		member, err := UnmarshalErrorFromJSON(raw)
+		if err != nil {
+			return err
+		}
+		if member != nil {
+			list = append(list, member)
+		}
+	}
+
+	*e = list
+	return nil
+}
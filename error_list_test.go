@@ -0,0 +1,188 @@
+package errkit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func TestErrorListUnmarshalJSON(t *testing.T) {
+	t.Run("It should decode error list serialized by MarshalJSON", func(t *testing.T) {
+		c := qt.New(t)
+		original := errkit.Append(errkit.New("First error", "key", "value"), errors.New("Second error"))
+		data, err := json.Marshal(original)
+		c.Assert(err, qt.IsNil)
+
+		var decoded errkit.ErrorList
+		c.Assert(json.Unmarshal(data, &decoded), qt.IsNil)
+		c.Assert(decoded, qt.HasLen, 2)
+		c.Assert(decoded.Error(), qt.Equals, original.Error())
+
+		again, err := json.Marshal(decoded)
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(again), qt.Equals, string(data))
+	})
+
+	t.Run("It should decode error lists used as causes", func(t *testing.T) {
+		c := qt.New(t)
+		list := errkit.Append(errkit.New("First error"), errkit.Append(errors.New("Second error"), errors.New("Third error")))
+		original := errkit.Wrap(list, "Batch failed", "batch", "b1")
+		data, err := json.Marshal(original)
+		c.Assert(err, qt.IsNil)
+
+		decoded, err := errkit.UnmarshalErrorFromJSON(data)
+		c.Assert(err, qt.IsNil)
+		c.Assert(decoded.Error(), qt.Equals, original.Error())
+
+		var cause errkit.ErrorList
+		c.Assert(errkit.As(decoded, &cause), qt.IsTrue)
+		c.Assert(cause, qt.HasLen, 3)
+
+		again, err := json.Marshal(decoded)
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(again), qt.Equals, string(data))
+	})
+
+	t.Run("It should decode JSON null as an empty list", func(t *testing.T) {
+		c := qt.New(t)
+		var decoded errkit.ErrorList
+		c.Assert(json.Unmarshal([]byte("null"), &decoded), qt.IsNil)
+		c.Assert(decoded, qt.HasLen, 0)
+
+		err, e := errkit.UnmarshalErrorFromJSON([]byte("null"))
+		c.Assert(e, qt.IsNil)
+		c.Assert(err, qt.IsNil)
+	})
+}
+
+func TestErrorListOperations(t *testing.T) {
+	errNotFound := errkit.NewSentinelErr("Not found")
+	errTransient := errkit.NewSentinelErr("Transient error")
+	list := errkit.ErrorList{
+		errkit.Wrap(errNotFound, "Failed to get PVC", "pvc", "a"),
+		errkit.Wrap(errTransient, "Failed to get PVC", "pvc", "b"),
+		errkit.New("Permission denied", "pvc", "c"),
+		errkit.WithStack(errTransient, "pvc", "d"),
+	}
+	messages := func(list errkit.ErrorList) []string {
+		var result []string
+		for _, err := range list {
+			result = append(result, err.Error())
+		}
+		return result
+	}
+
+	t.Run("It should filter members", func(t *testing.T) {
+		c := qt.New(t)
+		transient := list.Filter(func(err error) bool { return errkit.Is(err, errTransient) })
+		c.Assert(messages(transient), qt.DeepEquals, []string{"Failed to get PVC: Transient error", "Transient error"})
+		c.Assert(list.Filter(func(error) bool { return false }) == nil, qt.IsTrue)
+		c.Assert(list, qt.HasLen, 4)
+	})
+
+	t.Run("It should partition members", func(t *testing.T) {
+		c := qt.New(t)
+		notFound, rest := list.Partition(func(err error) bool { return errkit.Is(err, errNotFound) })
+		c.Assert(messages(notFound), qt.DeepEquals, []string{"Failed to get PVC: Not found"})
+		c.Assert(rest, qt.HasLen, 3)
+	})
+
+	t.Run("It should map members, dropping nil results", func(t *testing.T) {
+		c := qt.New(t)
+		mapped := list.Map(func(err error) error {
+			if errkit.Is(err, errNotFound) {
+				return nil
+			}
+			return errkit.Wrap(err, "Restore failed")
+		})
+		c.Assert(mapped, qt.HasLen, 3)
+		c.Assert(mapped[0].Error(), qt.Equals, "Restore failed: Failed to get PVC: Transient error")
+	})
+
+	t.Run("It should group members by key and keep keys in JSON", func(t *testing.T) {
+		c := qt.New(t)
+		groups := list.GroupBy(func(err error) string {
+			switch {
+			case errkit.Is(err, errNotFound):
+				return "ignore"
+			case errkit.Is(err, errTransient):
+				return "retry"
+			}
+			return "report"
+		})
+		c.Assert(groups, qt.HasLen, 3)
+		c.Assert(groups["retry"], qt.HasLen, 2)
+		c.Assert(groups["report"], qt.HasLen, 1)
+		c.Assert(errkit.ErrorList(nil).GroupBy(func(error) string { return "" }) == nil, qt.IsTrue)
+
+		data, err := json.Marshal(groups)
+		c.Assert(err, qt.IsNil)
+		c.Assert(strings.Count(string(data), `"schema_version"`), qt.Equals, 1)
+
+		var decoded errkit.ErrorMap
+		c.Assert(json.Unmarshal(data, &decoded), qt.IsNil)
+		c.Assert(decoded, qt.HasLen, 3)
+		c.Assert(messages(decoded["retry"].(errkit.ErrorList)), qt.DeepEquals, messages(groups["retry"].(errkit.ErrorList)))
+
+		fromJSON, err := errkit.UnmarshalErrorFromJSON(data)
+		c.Assert(err, qt.IsNil)
+		c.Assert(fromJSON.Error(), qt.Equals, groups.Error())
+	})
+
+	t.Run("It should remove matching members", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(list.Without(errTransient), qt.HasLen, 2)
+		c.Assert(list.Without(errTransient).Without(errNotFound).Err(), qt.ErrorMatches, "Permission denied")
+	})
+
+	t.Run("It should convert lists to errors", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(errkit.ErrorList{}.Err(), qt.IsNil)
+		c.Assert(errkit.ErrorList{errNotFound}.Err(), qt.Equals, errNotFound)
+		converted, ok := list.Err().(errkit.ErrorList)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(converted, qt.HasLen, len(list))
+	})
+}
+
+func FuzzErrorListJSONRoundTrip(f *testing.F) {
+	f.Add("First error", "Second error", "value", uint8(0))
+	f.Add("Resource not found", "", "", uint8(3))
+	f.Add("\"quoted\"", "line\nbreak", "<html>", uint8(7))
+
+	f.Fuzz(func(t *testing.T, msg1, msg2, detail string, depth uint8) {
+		var err error = errkit.Append(errkit.New(msg1, "key", detail), errors.New(msg2))
+		for i := 0; i < int(depth%4); i++ {
+			err = errkit.Wrap(err, msg1, "level", i)
+		}
+		err = errkit.Append(errkit.WithCause(errPredefinedSentinelError, err), errkit.Append(errkit.New(msg2), err))
+
+		data, e := json.Marshal(err)
+		if e != nil {
+			t.Fatalf("unable to marshal: %s", e.Error())
+		}
+
+		decoded, e := errkit.UnmarshalErrorFromJSON(data)
+		if e != nil {
+			t.Fatalf("unable to unmarshal %s: %s", data, e.Error())
+		}
+
+		again, e := json.Marshal(decoded)
+		if e != nil {
+			t.Fatalf("unable to marshal decoded error: %s", e.Error())
+		}
+
+		if string(again) != string(data) {
+			t.Fatalf("round trip mismatch\nexpected: %s\ngot     : %s", data, again)
+		}
+
+		if utf8.ValidString(msg1) && utf8.ValidString(msg2) && decoded.Error() != err.Error() {
+			t.Fatalf("error text mismatch\nexpected: %s\ngot     : %s", err.Error(), decoded.Error())
+		}
+	})
+}
@@ -0,0 +1,267 @@
+package errkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrorMap holds errors keyed by items they relate to, e.g. names of resources processed by a phase.
+// Members are ordered by key in every representation of the map, so it could be compared and logged deterministically.
+type ErrorMap map[string]error
+
+var _ error = (ErrorMap)(nil)
+var _ json.Marshaler = (ErrorMap)(nil)
+var _ json.Unmarshaler = (*ErrorMap)(nil)
+
+func (e ErrorMap) String() string {
+	l := DefaultLimits()
+	var sb strings.Builder
+	writeErrorText(&sb, e, l, 0)
+	return truncateText(sb.String(), l.maxBytes())
+}
+
+// writeText writes texts of members as a JSON object ordered by key.
+func (e ErrorMap) writeText(sb *strings.Builder, w textWriter, depth int) {
+	l := w.limits
+	var member strings.Builder
+	sb.WriteRune('{')
+	keys := e.keys()
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteRune(',')
+		}
+		if i == l.maxListLength() {
+			sb.WriteString(`"…":` + strconv.Quote(fmt.Sprintf("%d more errors truncated", len(keys)-i)))
+			break
+		}
+		if sb.Len() > l.maxBytes() {
+			// The result is going to be truncated anyway
+			break
+		}
+
+		member.Reset()
+		w.write(&member, e[k], depth+1)
+		sb.WriteString(strconv.Quote(k))
+		sb.WriteRune(':')
+		sb.WriteString(strconv.Quote(member.String()))
+	}
+	sb.WriteRune('}')
+}
+
+func (e ErrorMap) Error() string {
+	return e.String()
+}
+
+// Unwrap returns errors of the map ordered by key.
+func (e ErrorMap) Unwrap() []error {
+	result := make([]error, 0, len(e))
+	for _, k := range e.keys() {
+		result = append(result, e[k])
+	}
+	return result
+}
+
+// As allows error.As to work against any error in the map.
+func (e ErrorMap) As(target any) bool {
+	for _, k := range e.keys() {
+		if errors.As(e[k], target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is allows error.Is to work against any error in the map.
+func (e ErrorMap) Is(target error) bool {
+	for _, k := range e.keys() {
+		if errors.Is(e[k], target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns nil for a map without errors and the map itself otherwise.
+// It avoids returning an empty ErrorMap, or one having only nil errors, as a non-nil error.
+func (e ErrorMap) Err() error {
+	if len(e.keys()) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ToList returns errors of the map ordered by key, or nil if the map has no errors.
+func (e ErrorMap) ToList() ErrorList {
+	list := e.Unwrap()
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// keys returns keys of errors which are not nil, sorted.
+func (e ErrorMap) keys() []string {
+	keys := make([]string, 0, len(e))
+	for k, err := range e {
+		if err != nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (e ErrorMap) add(key string, err error) {
+	if existing, ok := e[key]; ok {
+		err = Append(existing, err)
+	}
+	e[key] = err
+}
+
+type jsonErrorMap struct {
+	SchemaVersion int                        `json:"schema_version,omitempty" description:"Version of the JSON schema, present only on the top level object. Absent in payloads produced before versioning was introduced."`
+	Message       string                     `json:"message" description:"Summary of the map, e.g. \"2 errors have occurred\"."`
+	Errors        map[string]json.RawMessage `json:"errors" description:"Errors keyed by items they relate to. Usually errors or error lists, but errors implementing json.Marshaler could produce any JSON value."`
+	Truncated     bool                       `json:"truncated,omitempty" description:"Set when some errors of the map were omitted because of size limits."`
+}
+
+func (e ErrorMap) MarshalJSON() ([]byte, error) {
+	l := DefaultLimits()
+	raw, err := e.marshalJSON(l, SchemaVersion, 0)
+	if err != nil || len(raw) <= l.maxBytes() {
+		return raw, err
+	}
+
+	return json.Marshal(jsonErrorMap{
+		SchemaVersion: SchemaVersion,
+		Message:       listMessage(len(e.keys())),
+		Errors:        map[string]json.RawMessage{},
+		Truncated:     true,
+	})
+}
+
+// marshalJSON serializes the map nested at the given depth, version is set only on the top level object.
+// When the number of errors exceeds the limit, errors with the greatest keys are omitted.
+func (e ErrorMap) marshalJSON(l Limits, version, depth int) ([]byte, error) {
+	keys := e.keys()
+	if len(keys) == 0 {
+		// no errors
+		return []byte("null"), nil
+	}
+
+	je := jsonErrorMap{
+		SchemaVersion: version,
+		Message:       listMessage(len(keys)),
+		Errors:        make(map[string]json.RawMessage, min(len(keys), l.maxListLength())),
+	}
+	for i, k := range keys {
+		if i == l.maxListLength() {
+			je.Truncated = true
+			break
+		}
+
+		raw, err := marshalNested(e[k], l, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		je.Errors[k] = raw
+	}
+
+	return json.Marshal(je)
+}
+
+// UnmarshalJSON decodes the representation produced by MarshalJSON.
+// Errors are decoded the same way as members of ErrorList.
+func (e *ErrorMap) UnmarshalJSON(data []byte) error {
+	var je jsonErrorMap
+	if err := json.Unmarshal(data, &je); err != nil {
+		return err
+	}
+
+	if err := checkSchemaVersion(je.SchemaVersion); err != nil {
+		return err
+	}
+
+	if je.Errors == nil {
+		*e = nil
+		return nil
+	}
+
+	result := make(ErrorMap, len(je.Errors))
+	for k, raw := range je.Errors {
+		member, err := UnmarshalErrorFromJSON(raw)
+		if err != nil {
+			return err
+		}
+		if member != nil {
+			result[k] = member
+		}
+	}
+
+	*e = result
+	return nil
+}
+
+// ErrorMapBuilder collects errors keyed by items, it is safe to add errors from multiple goroutines.
+// The zero value is ready to use.
+//
+//	var errs errkit.ErrorMapBuilder
+//	for _, pvc := range pvcs {
+//	    go func() {
+//	        errs.Add(pvc.Name, backup(pvc))
+//	    }()
+//	}
+//	...
+//	return errs.Err()
+type ErrorMapBuilder struct {
+	mu     sync.Mutex
+	errors ErrorMap
+}
+
+// Add adds err for the given key, nil errors are ignored.
+// Errors added for the same key are combined with Append.
+func (b *ErrorMapBuilder) Add(key string, err error) {
+	if err == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.errors == nil {
+		b.errors = ErrorMap{}
+	}
+	b.errors.add(key, err)
+}
+
+// Len returns the number of keys having errors.
+func (b *ErrorMapBuilder) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.errors.keys())
+}
+
+// ErrorMap returns a copy of collected errors, or nil if there are none.
+func (b *ErrorMapBuilder) ErrorMap() ErrorMap {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.errors) == 0 {
+		return nil
+	}
+
+	result := make(ErrorMap, len(b.errors))
+	for k, err := range b.errors {
+		result[k] = err
+	}
+	return result
+}
+
+// Err returns collected errors as an ErrorMap, or nil if there are none.
+func (b *ErrorMapBuilder) Err() error {
+	return b.ErrorMap().Err()
+}
@@ -0,0 +1,142 @@
+package errkit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func TestErrorMap(t *testing.T) {
+	errNotFound := errkit.NewSentinelErr("Not found")
+	errorMap := errkit.ErrorMap{
+		"pvc-b": errkit.Wrap(errNotFound, "Failed to backup"),
+		"pvc-a": &fs.PathError{Op: "open", Path: "/data", Err: fs.ErrPermission},
+		"pvc-c": nil,
+	}
+
+	t.Run("It should produce a deterministic string ordered by key", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(errorMap.Error(), qt.Equals, `{"pvc-a":"open /data: permission denied","pvc-b":"Failed to backup: Not found"}`)
+	})
+
+	t.Run("It should match errors of the map", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(errorMap, "Phase failed")
+		c.Assert(errkit.Is(err, errNotFound), qt.IsTrue)
+		c.Assert(errkit.Is(err, fs.ErrPermission), qt.IsTrue)
+		c.Assert(errkit.Is(err, fs.ErrClosed), qt.IsFalse)
+
+		pathErr, ok := errkit.AsType[*fs.PathError](err)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(pathErr.Path, qt.Equals, "/data")
+
+		unwrapped := errorMap.Unwrap()
+		c.Assert(unwrapped, qt.HasLen, 2)
+		c.Assert(unwrapped[1], qt.Equals, errorMap["pvc-b"])
+		c.Assert(errkit.CountIs(err, errNotFound), qt.Equals, 1)
+	})
+
+	t.Run("It should serialize the map as an object keyed by item", func(t *testing.T) {
+		c := qt.New(t)
+		data, err := json.Marshal(errkit.Wrap(errorMap, "Phase failed"))
+		c.Assert(err, qt.IsNil)
+
+		var parsed struct {
+			Cause struct {
+				Message string                     `json:"message"`
+				Errors  map[string]json.RawMessage `json:"errors"`
+			} `json:"cause"`
+		}
+		c.Assert(json.Unmarshal(data, &parsed), qt.IsNil)
+		c.Assert(parsed.Cause.Message, qt.Equals, "2 errors have occurred")
+		c.Assert(parsed.Cause.Errors, qt.HasLen, 2)
+		c.Assert(string(parsed.Cause.Errors["pvc-a"]), qt.Equals, `{"message":"open /data: permission denied"}`)
+
+		decoded, err := errkit.UnmarshalErrorFromJSON(data)
+		c.Assert(err, qt.IsNil)
+		c.Assert(decoded.Error(), qt.Equals, "Phase failed: "+errorMap.Error())
+	})
+
+	t.Run("It should decode the map from JSON", func(t *testing.T) {
+		c := qt.New(t)
+		data, err := json.Marshal(errorMap)
+		c.Assert(err, qt.IsNil)
+
+		var decoded errkit.ErrorMap
+		c.Assert(json.Unmarshal(data, &decoded), qt.IsNil)
+		c.Assert(decoded, qt.HasLen, 2)
+		c.Assert(decoded.Error(), qt.Equals, errorMap.Error())
+
+		fromJSON, err := errkit.UnmarshalErrorFromJSON(data)
+		c.Assert(err, qt.IsNil)
+		_, ok := fromJSON.(errkit.ErrorMap)
+		c.Assert(ok, qt.IsTrue)
+	})
+
+	t.Run("It should convert to and from ErrorList", func(t *testing.T) {
+		c := qt.New(t)
+		list := errorMap.ToList()
+		c.Assert(list, qt.HasLen, 2)
+		c.Assert(list[1], qt.Equals, errorMap["pvc-b"])
+
+		byMessage := errkit.Append(list, errors.New("open /data: permission denied")).(errkit.ErrorList).GroupBy(func(err error) string {
+			return err.Error()
+		})
+		c.Assert(byMessage, qt.HasLen, 2)
+		c.Assert(byMessage["open /data: permission denied"], qt.HasLen, 2)
+		c.Assert(errkit.ErrorMap{}.ToList() == nil, qt.IsTrue)
+	})
+
+	t.Run("It should treat a map having only nil errors as empty", func(t *testing.T) {
+		c := qt.New(t)
+		m := errkit.ErrorMap{"pvc-a": nil}
+		c.Assert(m.Err(), qt.IsNil)
+		c.Assert(m.ToList() == nil, qt.IsTrue)
+		list := errkit.ErrorMap{"pvc-a": nil, "pvc-b": errNotFound}.ToList()
+		c.Assert(list, qt.HasLen, 1)
+		c.Assert(list[0], qt.Equals, errNotFound)
+	})
+}
+
+func TestErrorMapBuilder(t *testing.T) {
+	t.Run("It should collect errors from multiple goroutines", func(t *testing.T) {
+		c := qt.New(t)
+		var b errkit.ErrorMapBuilder
+		c.Assert(b.Err(), qt.IsNil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var err error
+				if i%2 == 0 {
+					err = errkit.New("Backup failed", "index", i)
+				}
+				b.Add(fmt.Sprintf("pvc-%02d", i), err)
+			}()
+		}
+		wg.Wait()
+
+		c.Assert(b.Len(), qt.Equals, 25)
+		errorMap, ok := b.Err().(errkit.ErrorMap)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(errorMap, qt.HasLen, 25)
+		_, ok = errorMap["pvc-48"]
+		c.Assert(ok, qt.IsTrue)
+	})
+
+	t.Run("It should combine errors added for the same key", func(t *testing.T) {
+		c := qt.New(t)
+		var b errkit.ErrorMapBuilder
+		b.Add("pvc", errors.New("First error"))
+		b.Add("pvc", errors.New("Second error"))
+		c.Assert(b.ErrorMap()["pvc"], qt.ErrorMatches, `\["First error","Second error"\]`)
+	})
+}
@@ -3,13 +3,30 @@ package errkit
 import (
 	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"github.com/kanisterio/errkit/internal/bridge"
+	"github.com/kanisterio/errkit/internal/stack"
 )
 
+// maxStackDepth is the maximum number of frames captured for each error.
+const maxStackDepth = 32
+
 var _ error = (*errkitError)(nil)
 var _ interface {
 	Unwrap() error
 } = (*errkitError)(nil)
+var _ fmt.Formatter = (*errkitError)(nil)
+
+func init() {
+	bridge.NewError = func(err, cause error, details ...any) error {
+		return newErrorWithConfig(DefaultConfig(), err, "", cause, 3, ToErrorDetails(details))
+	}
+}
 
 // Make an aliases for errors.Is, errors.As, errors.Unwrap
 // To avoid additional imports
@@ -21,11 +38,45 @@ var (
 )
 
 type errkitError struct {
+	// error is the wrapped error, it is nil for errors created with a message.
 	error
-	cause   error
-	details ErrorDetails
+	message      string
+	cause        error
+	details      ErrorDetails
+	payload      any
+	severity     Severity
+	userMessage  string
+	safeDetails  []string
+	messageID    string
+	matchDetails bool
+
+	// origin is the error this one was copied from by annotators such as WithSeverity,
+	// the copy matches it with errors.Is.
+	origin *errkitError
+
+	// stack holds frames of the error except the ones shared with the stack of an errkit cause,
+	// callers is the number of frames of the whole stack.
 	stack   []uintptr
+	shared  sharedFrames
 	callers int
+
+	// cacheText is set when the texts of the wrapped error and of the whole chain of causes never change,
+	// only then the text of the error is cached.
+	cacheText bool
+
+	// text and location are computed lazily, the location only once for each error.
+	text     atomic.Pointer[errorText]
+	location atomic.Pointer[location]
+}
+
+type errorText struct {
+	limits Limits
+	text   string
+}
+
+type location struct {
+	function, file string
+	line           int
 }
 
 func (e *errkitError) Is(target error) bool {
@@ -34,12 +85,46 @@ func (e *errkitError) Is(target error) bool {
 	}
 
 	// Check if the target error is of the same type and value
-	return errors.Is(e.error, target)
+	if e.error != nil && errors.Is(e.error, target) {
+		return true
+	}
+
+	if e.origin != nil && (e.origin == target || e.origin.Is(target)) {
+		return true
+	}
+
+	if e.matchDetails {
+		for _, err := range e.details.errors() {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// As allows errors.As to work against the wrapped error, as well as against
+// detail values which are errors, when enabled with Config.MatchDetailErrors.
+func (e *errkitError) As(target any) bool {
+	if e.error != nil && errors.As(e.error, target) {
+		return true
+	}
+
+	if !e.matchDetails {
+		return false
+	}
+
+	for _, err := range e.details.errors() {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
 }
 
 // New returns an error with the given message.
 func New(message string, details ...any) error {
-	return newError(errors.New(message), 2, details...)
+	return newErrorWithConfig(DefaultConfig(), nil, message, nil, 2, ToErrorDetails(details))
 }
 
 // Wrap returns a new errkitError that has the given message and err as the cause.
@@ -48,9 +133,24 @@ func Wrap(err error, message string, details ...any) error {
 		return nil
 	}
 
-	e := newError(errors.New(message), 2, details...)
-	e.cause = err
-	return e
+	return newErrorWithConfig(DefaultConfig(), nil, message, err, 2, ToErrorDetails(details))
+}
+
+// WrapNoStack is the same as Wrap, but does not capture the stack, so the returned error has no location.
+// It is intended for hot paths, where the cost of capturing the stack for each error is noticeable,
+// and the location of the cause is sufficient.
+func WrapNoStack(err error, message string, details ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	return &errkitError{
+		message:      message,
+		cause:        err,
+		details:      ToErrorDetails(details),
+		matchDetails: DefaultConfig().MatchDetailErrors,
+		cacheText:    immutableText(err),
+	}
 }
 
 // WithStack wraps the given error with a struct that when serialized to JSON will return
@@ -68,8 +168,7 @@ func WithStack(err error, details ...any) error {
 		return nil
 	}
 
-	e := newError(err, 2, details...)
-	return e
+	return newError(err, nil, 2, details...)
 }
 
 // WithCause adds a cause to the given pure error.
@@ -94,23 +193,125 @@ func WithCause(err, cause error, details ...any) error {
 		return nil
 	}
 
-	e := newError(err, 2, details...)
-	e.cause = cause
-	return e
+	return newError(err, cause, 2, details...)
 }
 
-func newError(err error, stackDepth int, details ...any) *errkitError {
+func newError(err, cause error, stackDepth int, details ...any) *errkitError {
+	return newErrorWithConfig(DefaultConfig(), err, "", cause, stackDepth+1, ToErrorDetails(details))
+}
+
+// newErrorWithConfig creates an error either wrapping err or having the given message, when err is nil.
+func newErrorWithConfig(cfg Config, err error, message string, cause error, stackDepth int, details ErrorDetails) *errkitError {
 	result := &errkitError{
-		error:   err,
-		details: ToErrorDetails(details),
-		stack:   make([]uintptr, 1),
+		error:        err,
+		message:      message,
+		cause:        cause,
+		details:      details,
+		matchDetails: cfg.MatchDetailErrors,
+		cacheText:    immutableText(err) && immutableText(cause),
+	}
+	if imported := importedStack(err); len(imported) > 0 {
+		// The wrapped error carries its own stack trace (e.g. created by pkg/errors), its frames are kept instead
+		result.setStack(imported)
+	} else if cfg.captureStack(cause) {
+		// Capturing the stack into a buffer on the goroutine stack, so only the used part is allocated
+		var buf [maxStackDepth]uintptr
+		pcs := buf[:]
+		if depth := cfg.stackDepth(); depth > maxStackDepth {
+			pcs = make([]uintptr, depth)
+		} else {
+			pcs = pcs[:depth]
+		}
+
+		n := runtime.Callers(stackDepth+1+cfg.CallerSkip, pcs)
+		result.setStack(pcs[:n])
+	}
+
+	return result
+}
+
+// setStack stores a copy of the stack of the error. Outer frames which are also present in the stack
+// of an errkit cause are not copied, only their position in the stack of the cause is kept.
+func (e *errkitError) setStack(pcs []uintptr) {
+	e.callers = len(pcs)
+	if cause, ok := e.cause.(*errkitError); ok && cause.callers > 0 {
+		var buf [maxStackDepth]uintptr
+		e.shared = repeatedFrames(pcs, cause.appendStack(buf[:0]))
+	}
+
+	end := e.shared.start + e.shared.length
+	e.stack = make([]uintptr, 0, len(pcs)-e.shared.length)
+	e.stack = append(e.stack, pcs[:e.shared.start]...)
+	e.stack = append(e.stack, pcs[end:]...)
+}
+
+// appendStack appends the whole stack of the error to pcs, restoring the frames shared with the cause.
+func (e *errkitError) appendStack(pcs []uintptr) []uintptr {
+	if e.shared.length == 0 {
+		return append(pcs, e.stack...)
 	}
 
-	result.callers = runtime.Callers(stackDepth+1, result.stack)
+	var buf [maxStackDepth]uintptr
+	causeStack := e.cause.(*errkitError).appendStack(buf[:0])
+	pcs = append(pcs, e.stack[:e.shared.start]...)
+	pcs = append(pcs, causeStack[e.shared.causeStart:e.shared.causeStart+e.shared.length]...)
+	return append(pcs, e.stack[e.shared.start:]...)
+}
+
+// clone returns a copy of the error, sharing its stack and details.
+func (e *errkitError) clone() *errkitError {
+	return &errkitError{
+		error:        e.error,
+		message:      e.message,
+		cause:        e.cause,
+		details:      e.details,
+		payload:      e.payload,
+		severity:     e.severity,
+		userMessage:  e.userMessage,
+		safeDetails:  e.safeDetails,
+		messageID:    e.messageID,
+		stack:        e.stack,
+		shared:       e.shared,
+		callers:      e.callers,
+		matchDetails: e.matchDetails,
+		cacheText:    e.cacheText,
+	}
+}
+
+// annotate returns a copy of an errkit error, which still matches it with errors.Is,
+// or wraps any other error the same way as WithStack does, and applies fn to the result.
+// Returns nil when nil is passed.
+func annotate(err error, fn func(e *errkitError)) error {
+	if err == nil {
+		return nil
+	}
 
+	var result *errkitError
+	if e, ok := err.(*errkitError); ok {
+		result = e.clone()
+		result.origin = e
+	} else {
+		result = newError(err, nil, 3)
+	}
+	fn(result)
 	return result
 }
 
+// errorStringType is the type of errors created by errors.New, their text never changes.
+var errorStringType = reflect.TypeOf(errors.New(""))
+
+// immutableText reports whether the text of err never changes, so it could be cached by errors wrapping err.
+// Other errors, e.g. ErrorList, ErrorMap or errors of other packages, could be modified after they were wrapped.
+func immutableText(err error) bool {
+	switch e := err.(type) {
+	case nil:
+		return true
+	case *errkitError:
+		return e.cacheText
+	}
+	return reflect.TypeOf(err) == errorStringType
+}
+
 // Unwrap returns the chained causal error, or nil if there is no causal error.
 func (e *errkitError) Unwrap() error {
 	return e.cause
@@ -118,6 +319,9 @@ func (e *errkitError) Unwrap() error {
 
 // Message returns the message for this error.
 func (e *errkitError) Message() string {
+	if e.error == nil {
+		return e.message
+	}
 	return e.error.Error()
 }
 
@@ -127,15 +331,96 @@ func (e *errkitError) Details() ErrorDetails {
 }
 
 // Error returns a string representation of the error.
+// The length of the result and the depth of included causes are restricted by DefaultLimits.
+// When the chain consists only of errkit errors and errors created by errors.New, the result is computed once
+// and reused, unless limits are changed. Otherwise it is computed on each call, since wrapped errors could change.
 func (e *errkitError) Error() string {
-	if e.cause == nil {
-		return e.error.Error()
+	l := DefaultLimits()
+	if !e.cacheText {
+		return e.buildText(l)
 	}
 
-	return fmt.Sprintf("%s: %s", e.error.Error(), e.cause.Error())
+	if cached := e.text.Load(); cached != nil && cached.limits == l {
+		return cached.text
+	}
+
+	text := e.buildText(l)
+	e.text.Store(&errorText{limits: l, text: text})
+	return text
+}
+
+// buildText returns messages of the error and its causes, restricted by limits.
+func (e *errkitError) buildText(l Limits) string {
+	var sb strings.Builder
+	writeErrorText(&sb, e, l, 0)
+	return truncateText(sb.String(), l.maxBytes())
+}
+
+// Location returns the function, file and line where this error was created.
+// Empty values are returned for errors created without capturing the stack.
+func (e *errkitError) Location() (function, file string, line int) {
+	if e.callers == 0 {
+		return "", "", 0
+	}
+
+	if loc := e.location.Load(); loc != nil {
+		return loc.function, loc.file, loc.line
+	}
+
+	loc := &location{}
+	loc.function, loc.file, loc.line = stack.GetLocationFromStack(e.stack, len(e.stack))
+	e.location.Store(loc)
+	return loc.function, loc.file, loc.line
+}
+
+// StackTrace returns the stack captured when this error was created.
+// The result is formatted the same way as the stack trace of pkg/errors.
+func (e *errkitError) StackTrace() StackTrace {
+	return toStackTrace(e.appendStack(make([]uintptr, 0, e.callers)))
+}
+
+// Format implements fmt.Formatter, following the conventions of pkg/errors:
+//
+//	%s, %v  the same as Error()
+//	%q      the quoted Error()
+//	%+v     every layer of the chain with its details and stack trace
+func (e *errkitError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			if e.cause != nil {
+				fmt.Fprintf(s, "%+v\n", e.cause)
+			}
+			_, _ = io.WriteString(s, e.Message())
+			if len(e.details) > 0 {
+				fmt.Fprintf(s, " %s", e.details)
+			}
+			e.formatStack(s, verb)
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
 }
 
 // MarshalJSON is helping json logger to log error in a json format
 func (e *errkitError) MarshalJSON() ([]byte, error) {
 	return MarshalErrkitErrorToJSON(e)
 }
+
+// formatStack prints the stack trace, omitting frames shared with the stack of the errkit cause,
+// which was already printed. Frames of other causes are never omitted.
+func (e *errkitError) formatStack(s fmt.State, verb rune) {
+	st := toStackTrace(e.stack)
+	if e.shared.length == 0 {
+		st.Format(s, verb)
+		return
+	}
+
+	st[:e.shared.start].Format(s, verb)
+	fmt.Fprintf(s, "\n[...%d frames repeated above...]", e.shared.length)
+	st[e.shared.start:].Format(s, verb)
+}
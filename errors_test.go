@@ -87,12 +87,20 @@ func filenameCheck(_ error, data []byte) error {
 }
 
 func getStackCheck(fnName string, lineNumber int) Check {
+	locationCheck := getLocationCheck(fnName, lineNumber)
 	return func(err error, data []byte) error {
 		e := filenameCheck(err, data)
 		if e != nil {
 			return e
 		}
 
+		return locationCheck(err, data)
+	}
+}
+
+// getLocationCheck is the same as getStackCheck, but does not require an error to be created in this file
+func getLocationCheck(fnName string, lineNumber int) Check {
+	return func(_ error, data []byte) error {
 		var unmarshalledError struct {
 			LineNumber int    `json:"linenumber,omitempty"`
 			Function   string `json:"function,omitempty"`
@@ -0,0 +1,9 @@
+// Package bridge allows errkit subpackages to create errkit errors
+// with the stack captured at their own caller, without exposing
+// such constructors in the public API of errkit.
+package bridge
+
+// NewError creates an errkit error from err, cause and details.
+// The stack is captured at the caller of the function which invokes NewError.
+// It is set by the errkit package during initialization.
+var NewError func(err, cause error, details ...any) error
@@ -0,0 +1,186 @@
+// Package toml parses the subset of TOML used by message catalogs of errkit.
+package toml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parse parses the subset of TOML used by message catalogs: tables, comments
+// and keys with string values, either basic or literal. Keys of tables are joined with dots,
+// so the result is a flat map, e.g. `[restore]` followed by `failed = "..."` gives "restore.failed".
+func Parse(data []byte) (map[string]string, error) {
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("toml: invalid UTF-8")
+	}
+
+	result := map[string]string{}
+	table := ""
+	for n, line := range strings.Split(string(data), "\n") {
+		p := parser{line: strings.TrimSpace(strings.TrimSuffix(line, "\r"))}
+		if err := p.parseLine(result, &table); err != nil {
+			return nil, fmt.Errorf("toml: line %d: %w", n+1, err)
+		}
+	}
+	return result, nil
+}
+
+type parser struct {
+	line string
+}
+
+func (p *parser) parseLine(result map[string]string, table *string) error {
+	if p.line == "" || p.line[0] == '#' {
+		return nil
+	}
+
+	if p.line[0] == '[' {
+		if strings.HasPrefix(p.line, "[[") {
+			return fmt.Errorf("arrays of tables are not supported")
+		}
+		p.line = strings.TrimSpace(p.line[1:])
+		key, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(p.line, "]") {
+			return fmt.Errorf("expected ']'")
+		}
+		p.line = strings.TrimSpace(p.line[1:])
+		*table = key
+		return p.parseEnd()
+	}
+
+	key, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(p.line, "=") {
+		return fmt.Errorf("expected '=' after key %q", key)
+	}
+	p.line = strings.TrimSpace(p.line[1:])
+
+	value, err := p.parseString()
+	if err != nil {
+		return fmt.Errorf("value of %q: %w", key, err)
+	}
+
+	if *table != "" {
+		key = *table + "." + key
+	}
+	if _, ok := result[key]; ok {
+		return fmt.Errorf("duplicate key %q", key)
+	}
+	result[key] = value
+	return p.parseEnd()
+}
+
+// parseKey parses a dotted key consisting of bare and quoted parts.
+func (p *parser) parseKey() (string, error) {
+	var parts []string
+	for {
+		var part string
+		if p.line != "" && (p.line[0] == '"' || p.line[0] == '\'') {
+			s, err := p.parseString()
+			if err != nil {
+				return "", err
+			}
+			part = s
+		} else {
+			end := strings.IndexFunc(p.line, func(r rune) bool {
+				return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-')
+			})
+			if end < 0 {
+				end = len(p.line)
+			}
+			if end == 0 {
+				return "", fmt.Errorf("expected a key")
+			}
+			part, p.line = p.line[:end], strings.TrimSpace(p.line[end:])
+		}
+		parts = append(parts, part)
+
+		if !strings.HasPrefix(p.line, ".") {
+			return strings.Join(parts, "."), nil
+		}
+		p.line = strings.TrimSpace(p.line[1:])
+	}
+}
+
+// parseString parses a basic "..." or a literal '...' single line string.
+func (p *parser) parseString() (string, error) {
+	switch {
+	case strings.HasPrefix(p.line, `"""`), strings.HasPrefix(p.line, `'''`):
+		return "", fmt.Errorf("multi-line strings are not supported")
+	case strings.HasPrefix(p.line, "'"):
+		end := strings.IndexByte(p.line[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		s := p.line[1 : end+1]
+		p.line = strings.TrimSpace(p.line[end+2:])
+		return s, nil
+	case strings.HasPrefix(p.line, `"`):
+		return p.parseBasicString()
+	}
+	return "", fmt.Errorf("only string values are supported")
+}
+
+func (p *parser) parseBasicString() (string, error) {
+	var sb strings.Builder
+	for i := 1; i < len(p.line); i++ {
+		switch c := p.line[i]; c {
+		case '"':
+			p.line = strings.TrimSpace(p.line[i+1:])
+			return sb.String(), nil
+		case '\\':
+			i++
+			if i == len(p.line) {
+				return "", fmt.Errorf("unterminated string")
+			}
+			switch e := p.line[i]; e {
+			case 'b':
+				sb.WriteByte('\b')
+			case 't':
+				sb.WriteByte('\t')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'r':
+				sb.WriteByte('\r')
+			case '"', '\\':
+				sb.WriteByte(e)
+			case 'u', 'U':
+				size := 4
+				if e == 'U' {
+					size = 8
+				}
+				if i+size >= len(p.line) {
+					return "", fmt.Errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(p.line[i+1:i+1+size], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(code)) {
+					return "", fmt.Errorf("invalid unicode escape")
+				}
+				sb.WriteRune(rune(code))
+				i += size
+			default:
+				return "", fmt.Errorf("invalid escape sequence \\%c", e)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// parseEnd checks that nothing but a comment follows a parsed value.
+func (p *parser) parseEnd() error {
+	if p.line != "" && p.line[0] != '#' {
+		return fmt.Errorf("unexpected %q", p.line)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package errkit
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// Limits restricts the size of serialized errors, protecting logs from
+// errors produced by runaway loops, e.g. huge error lists or very deep cause chains.
+// Output exceeding a limit is truncated and marked explicitly:
+// objects of errors and lists get `"truncated": true`,
+// long detail values are replaced with "TRUNCATED:(…)" strings,
+// and text returned by Error() gets "…(truncated)" suffix.
+//
+// A zero value of a field means the default limit, a negative value disables the limit.
+type Limits struct {
+	// MaxCauseDepth is the maximum depth of nested causes and lists. Default is 64.
+	MaxCauseDepth int
+	// MaxListLength is the maximum number of serialized members of an ErrorList. Default is 128.
+	MaxListLength int
+	// MaxDetails is the maximum number of serialized details of a single error. Default is 64.
+	MaxDetails int
+	// MaxDetailValueLength is the maximum length of a serialized detail value in bytes. Default is 8 KiB.
+	MaxDetailValueLength int
+	// MaxBytes is the maximum length of the whole serialized error in bytes. Default is 512 KiB.
+	MaxBytes int
+}
+
+const (
+	truncated       = "TRUNCATED"
+	truncatedSuffix = "…(truncated)"
+)
+
+var defaultLimits atomic.Pointer[Limits]
+
+// SetDefaultLimits replaces the limits applied when errors are serialized.
+func SetDefaultLimits(l Limits) {
+	defaultLimits.Store(&l)
+}
+
+// DefaultLimits returns the limits applied when errors are serialized.
+func DefaultLimits() Limits {
+	if l := defaultLimits.Load(); l != nil {
+		return *l
+	}
+	return Limits{}
+}
+
+func (l Limits) maxCauseDepth() int        { return limit(l.MaxCauseDepth, 64) }
+func (l Limits) maxListLength() int        { return limit(l.MaxListLength, 128) }
+func (l Limits) maxDetails() int           { return limit(l.MaxDetails, 64) }
+func (l Limits) maxDetailValueLength() int { return limit(l.MaxDetailValueLength, 8<<10) }
+func (l Limits) maxBytes() int             { return limit(l.MaxBytes, 512<<10) }
+
+func limit(value, defaultValue int) int {
+	switch {
+	case value < 0:
+		return math.MaxInt
+	case value == 0:
+		return defaultValue
+	}
+	return value
+}
+
+// truncateText cuts s to at most maxLen bytes, including the truncation suffix,
+// without splitting UTF-8 characters.
+func truncateText(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+
+	n := maxLen - len(truncatedSuffix)
+	if n < 0 {
+		n = 0
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + truncatedSuffix
+}
+
+// truncatedValue replaces a serialized detail value exceeding the limit.
+func truncatedValue(raw []byte, maxLen int) string {
+	return fmt.Sprintf("%s:(%s)", truncated, truncateText(string(raw), maxLen))
+}
+
+// writeErrorText writes the text of an error nested at the given depth, respecting limits.
+func writeErrorText(sb *strings.Builder, err error, l Limits, depth int) {
+	textWriter{limits: l, message: errorMessage}.write(sb, err, depth)
+}
+
+// textWriter writes the text of an error, the same way as Error() does: messages of a chain are separated by colons,
+// members of ErrorList and ErrorMap are written as JSON arrays and objects of their texts.
+type textWriter struct {
+	limits Limits
+	// message returns the message of a single error of a chain, along with its cause.
+	// Errors it does not accept are written as returned by their Error().
+	message func(err error) (message string, cause error, ok bool)
+}
+
+// errorMessage returns messages of errkit errors as Error() writes them.
+func errorMessage(err error) (string, error, bool) {
+	if e, ok := err.(*errkitError); ok {
+		return e.Message(), e.cause, true
+	}
+	return "", nil, false
+}
+
+// write writes the text of an error nested at the given depth.
+func (w textWriter) write(sb *strings.Builder, err error, depth int) {
+	switch {
+	case depth > w.limits.maxCauseDepth():
+		sb.WriteString(truncatedSuffix)
+		return
+	case sb.Len() > w.limits.maxBytes():
+		// The result is going to be truncated anyway
+		return
+	}
+
+	switch e := err.(type) {
+	case ErrorList:
+		e.writeText(sb, w, depth)
+	case ErrorMap:
+		e.writeText(sb, w, depth)
+	default:
+		message, cause, ok := w.message(err)
+		if !ok {
+			sb.WriteString(err.Error())
+			return
+		}
+
+		sb.WriteString(message)
+		if cause != nil {
+			sb.WriteString(": ")
+			w.write(sb, cause, depth+1)
+		}
+	}
+}
+
+// shortMessage returns the message of err without its causes, used for truncated errors.
+func shortMessage(err error, l Limits) string {
+	var message string
+	switch e := err.(type) {
+	case ErrorList:
+		message = listMessage(len(e))
+	case ErrorMap:
+		message = listMessage(len(e.keys()))
+	case interface{ Message() string }:
+		message = e.Message()
+	default:
+		message = err.Error()
+	}
+	return truncateText(message, l.maxDetailValueLength())
+}
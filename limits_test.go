@@ -0,0 +1,184 @@
+package errkit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func setLimits(t *testing.T, l errkit.Limits) {
+	t.Helper()
+	errkit.SetDefaultLimits(l)
+	t.Cleanup(func() {
+		errkit.SetDefaultLimits(errkit.Limits{})
+	})
+}
+
+func TestLimits(t *testing.T) {
+	t.Run("It should truncate deep cause chains", func(t *testing.T) {
+		c := qt.New(t)
+		setLimits(t, errkit.Limits{MaxCauseDepth: 2})
+
+		err := errkit.New("Level 0")
+		for i := 1; i < 10; i++ {
+			err = errkit.Wrap(err, fmt.Sprintf("Level %d", i))
+		}
+
+		c.Assert(err.Error(), qt.Equals, "Level 9: Level 8: Level 7: …(truncated)")
+
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		var parsed struct {
+			Cause struct {
+				Cause struct {
+					Message   string          `json:"message"`
+					Truncated bool            `json:"truncated"`
+					Cause     json.RawMessage `json:"cause"`
+				} `json:"cause"`
+			} `json:"cause"`
+		}
+		c.Assert(json.Unmarshal(data, &parsed), qt.IsNil)
+		c.Assert(parsed.Cause.Cause.Message, qt.Equals, "Level 7")
+		c.Assert(parsed.Cause.Cause.Truncated, qt.IsFalse)
+		c.Assert(string(parsed.Cause.Cause.Cause), qt.Equals, `{"message":"Level 6","truncated":true}`)
+	})
+
+	t.Run("It should truncate long error lists", func(t *testing.T) {
+		c := qt.New(t)
+		setLimits(t, errkit.Limits{MaxListLength: 2})
+
+		var err error
+		for i := 0; i < 5; i++ {
+			err = errkit.Append(err, errkit.NewSentinelErr(fmt.Sprintf("Error %d", i)))
+		}
+
+		c.Assert(err.Error(), qt.Equals, `["Error 0","Error 1","…(3 more errors truncated)"]`)
+
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(data), qt.Equals, `{"schema_version":1,"message":"5 errors have occurred","errors":[{"message":"Error 0"},{"message":"Error 1"}],"truncated":true}`)
+
+		data, e = json.Marshal(errkit.Wrap(err, "Wrapped"))
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(data), qt.Contains, `"cause":{"message":"5 errors have occurred","errors":[{"message":"Error 0"},{"message":"Error 1"}],"truncated":true}`)
+	})
+
+	t.Run("It should truncate details", func(t *testing.T) {
+		c := qt.New(t)
+		setLimits(t, errkit.Limits{MaxDetails: 2, MaxDetailValueLength: 20})
+
+		err := errkit.New("Some error", "a", 1, "b", strings.Repeat("x", 100), "c", 3)
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+
+		var parsed struct {
+			Details   map[string]any `json:"details"`
+			Truncated bool           `json:"truncated"`
+		}
+		c.Assert(json.Unmarshal(data, &parsed), qt.IsNil)
+		c.Assert(parsed.Truncated, qt.IsTrue)
+		c.Assert(parsed.Details, qt.DeepEquals, map[string]any{
+			"a": float64(1),
+			"b": `TRUNCATED:("xxxxx…(truncated))`,
+		})
+	})
+
+	t.Run("It should restrict the total size", func(t *testing.T) {
+		c := qt.New(t)
+		setLimits(t, errkit.Limits{MaxBytes: 200})
+
+		var list error
+		for i := 0; i < 50; i++ {
+			list = errkit.Append(list, errkit.New(fmt.Sprintf("Error %d", i)))
+		}
+		err := errkit.Wrap(list, "Batch failed")
+
+		c.Assert(len(err.Error()) <= 200, qt.IsTrue)
+		c.Assert(strings.HasSuffix(err.Error(), "…(truncated)"), qt.IsTrue)
+
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		c.Assert(len(data) <= 400, qt.IsTrue, qt.Commentf("got %d bytes", len(data)))
+
+		var parsed struct {
+			Message   string          `json:"message"`
+			Truncated bool            `json:"truncated"`
+			Cause     json.RawMessage `json:"cause"`
+		}
+		c.Assert(json.Unmarshal(data, &parsed), qt.IsNil)
+		c.Assert(parsed.Message, qt.Equals, "Batch failed")
+		c.Assert(parsed.Truncated, qt.IsTrue)
+		c.Assert(parsed.Cause, qt.IsNil)
+
+		data, e = json.Marshal(list)
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(data), qt.Equals, `{"schema_version":1,"message":"50 errors have occurred","errors":[],"truncated":true}`)
+	})
+
+	t.Run("It should allow disabling limits", func(t *testing.T) {
+		c := qt.New(t)
+		setLimits(t, errkit.Limits{MaxListLength: -1})
+
+		var err error
+		for i := 0; i < 200; i++ {
+			err = errkit.Append(err, errkit.NewSentinelErr("Error"))
+		}
+
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		c.Assert(strings.Count(string(data), `{"message":"Error"}`), qt.Equals, 200)
+	})
+
+	t.Run("It should recompute cached error text when limits change", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(errkit.Wrap(errkit.New("Root cause"), "Middle error"), "Top error")
+		c.Assert(err.Error(), qt.Equals, "Top error: Middle error: Root cause")
+
+		setLimits(t, errkit.Limits{MaxCauseDepth: 1})
+		c.Assert(err.Error(), qt.Equals, "Top error: Middle error: …(truncated)")
+	})
+
+	t.Run("It should not cache text of errors wrapping errors which could change", func(t *testing.T) {
+		c := qt.New(t)
+		errorMap := errkit.ErrorMap{"pvc-a": errors.New("First failure")}
+		err := errkit.Wrap(errkit.WithStack(errorMap), "Phase failed")
+		c.Assert(err.Error(), qt.Equals, `Phase failed: {"pvc-a":"First failure"}`)
+
+		errorMap["pvc-b"] = errors.New("Second failure")
+		c.Assert(err.Error(), qt.Equals, `Phase failed: {"pvc-a":"First failure","pvc-b":"Second failure"}`)
+
+		var list errkit.ErrorList
+		list = append(list, errors.New("First failure"), errors.New("Second failure"))
+		err = errkit.Wrap(list, "Phase failed")
+		c.Assert(err.Error(), qt.Equals, `Phase failed: ["First failure","Second failure"]`)
+
+		list[0] = errors.New("Replaced failure")
+		c.Assert(err.Error(), qt.Equals, `Phase failed: ["Replaced failure","Second failure"]`)
+	})
+}
+
+func TestErrorTextConcurrency(t *testing.T) {
+	t.Run("It should return the same text when called concurrently", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.New("Root cause")
+		for i := 0; i < 10; i++ {
+			err = errkit.Wrap(err, fmt.Sprintf("Wrapped error %d", i))
+		}
+
+		results := make(chan string, 8)
+		for i := 0; i < cap(results); i++ {
+			go func() {
+				results <- err.Error()
+			}()
+		}
+		for i := 0; i < cap(results); i++ {
+			c.Assert(<-results, qt.Equals, "Wrapped error 9: Wrapped error 8: Wrapped error 7: Wrapped error 6: Wrapped error 5: "+
+				"Wrapped error 4: Wrapped error 3: Wrapped error 2: Wrapped error 1: Wrapped error 0: Root cause")
+		}
+	})
+}
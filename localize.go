@@ -0,0 +1,266 @@
+package errkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kanisterio/errkit/internal/toml"
+)
+
+// DefaultLanguage is the fallback language of catalogs, used when a message has no translation to the requested language.
+const DefaultLanguage = "en"
+
+// Catalog holds translations of messages, keyed by language and message ID.
+// Messages could refer to safe details of an error as named parameters, using their names in braces:
+//
+//	restore.failed = "Wiederherstellung von {pvc} fehlgeschlagen"
+//
+// Only details passed to WithMessageID or WithUserMessage, or marked with WithSafeDetails, are safe.
+// Others are never substituted, since translated messages are intended for end users, e.g. details
+// added by New have to be marked before they could be used as parameters:
+//
+//	err := errkit.New("Restore failed", "pvc", pvc.Name)
+//	return errkit.WithMessageID(errkit.WithSafeDetails(err, "pvc"), "restore.failed")
+//
+// It is safe to use a Catalog from multiple goroutines.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+	fallback string
+}
+
+// NewCatalog returns an empty catalog, which falls back to the given language,
+// or to DefaultLanguage when it is empty.
+func NewCatalog(fallback string) *Catalog {
+	if fallback == "" {
+		fallback = DefaultLanguage
+	}
+	return &Catalog{messages: map[string]map[string]string{}, fallback: normalizeLanguage(fallback)}
+}
+
+// LoadCatalog returns a catalog with translations from files of fsys matching pattern, e.g. an embed.FS:
+//
+//	//go:embed locales
+//	var locales embed.FS
+//	...
+//	catalog, err := errkit.LoadCatalog(locales, "locales/*", "en")
+//
+// The language of each file is its name without extension, e.g. "de.toml" or "pt-BR.json".
+// JSON files hold objects, TOML files hold keys with string values. Nested objects and TOML tables
+// are flattened, so their keys are joined with dots into message IDs.
+func LoadCatalog(fsys fs.FS, pattern, fallback string) (*Catalog, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewCatalog(fallback)
+	for _, file := range files {
+		ext := path.Ext(file)
+		if ext != ".json" && ext != ".toml" {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		var messages map[string]string
+		if ext == ".json" {
+			messages, err = parseCatalogJSON(data)
+		} else {
+			messages, err = toml.Parse(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to load catalog %s: %w", file, err)
+		}
+
+		c.Add(strings.TrimSuffix(path.Base(file), ext), messages)
+	}
+	return c, nil
+}
+
+// Add adds translations of messages to the given language, replacing existing ones having the same IDs.
+func (c *Catalog) Add(lang string, messages map[string]string) {
+	lang = normalizeLanguage(lang)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[string]string, len(messages))
+	}
+	for id, message := range messages {
+		c.messages[lang][id] = message
+	}
+}
+
+// Languages returns languages having translations, sorted.
+func (c *Catalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]string, 0, len(c.messages))
+	for lang := range c.messages {
+		result = append(result, lang)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Message returns the translation of the message with the given ID.
+// When there is no translation to lang, its base language is tried, e.g. "de" for "de-AT",
+// and then the fallback language of the catalog.
+func (c *Catalog) Message(id, lang string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, l := range c.candidates(lang) {
+		if message, ok := c.messages[l][id]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+func (c *Catalog) candidates(lang string) []string {
+	lang = normalizeLanguage(lang)
+	result := []string{lang}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		result = append(result, base)
+	}
+	return append(result, c.fallback)
+}
+
+// Localize returns the text of err in the given language, in the same format as Error() does.
+// Messages of errors having IDs are translated, with their safe details as named parameters,
+// messages of other errors are kept as they are.
+func (c *Catalog) Localize(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+
+	l := DefaultLimits()
+	w := textWriter{
+		limits: l,
+		message: func(err error) (string, error, bool) {
+			return c.localizedMessage(err, lang)
+		},
+	}
+
+	var sb strings.Builder
+	w.write(&sb, err, 0)
+	return truncateText(sb.String(), l.maxBytes())
+}
+
+// localizedMessage returns the translated message of a single error of a chain along with its cause,
+// or its original message if there is no translation.
+func (c *Catalog) localizedMessage(err error, lang string) (string, error, bool) {
+	switch e := err.(type) {
+	case *errkitError:
+		if e.messageID == "" && e.error != nil {
+			// The wrapped error could have its own ID, e.g. when it is annotated with WithStack
+			return c.Localize(e.error, lang), e.cause, true
+		}
+		return c.translate(e.messageID, e.Message(), e.details, e.safeDetails, lang), e.cause, true
+	case *jsonError:
+		return c.translate(e.MessageID, e.Message, e.Details, e.SafeDetails, lang), e.Unwrap(), true
+	}
+	return "", nil, false
+}
+
+// translate returns the translated message with safe details substituted, or message if there is no translation.
+func (c *Catalog) translate(id, message string, details ErrorDetails, safeDetails []string, lang string) string {
+	if id == "" {
+		return message
+	}
+
+	translated, ok := c.Message(id, lang)
+	if !ok {
+		return message
+	}
+	return substituteSafeDetails(translated, details, safeDetails)
+}
+
+func normalizeLanguage(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+}
+
+// parseCatalogJSON parses an object of messages, flattening nested objects into dotted IDs.
+func parseCatalogJSON(data []byte) (map[string]string, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	if err := flattenMessages(result, "", doc); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func flattenMessages(result map[string]string, prefix string, doc map[string]any) error {
+	for k, v := range doc {
+		id := k
+		if prefix != "" {
+			id = prefix + "." + k
+		}
+
+		switch value := v.(type) {
+		case string:
+			result[id] = value
+		case map[string]any:
+			if err := flattenMessages(result, id, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("value of %q: only string values are supported", id)
+		}
+	}
+	return nil
+}
+
+var defaultCatalog atomic.Pointer[Catalog]
+
+// SetDefaultCatalog replaces the catalog used by Localize.
+func SetDefaultCatalog(c *Catalog) {
+	defaultCatalog.Store(c)
+}
+
+// DefaultCatalog returns the catalog used by Localize, or nil if it is not set.
+func DefaultCatalog() *Catalog {
+	return defaultCatalog.Load()
+}
+
+// Localize returns the text of err in the given language using the default catalog,
+// see Catalog.Localize. Without the default catalog the result is the same as Error().
+func Localize(err error, lang string) string {
+	c := DefaultCatalog()
+	if c == nil {
+		c = NewCatalog("")
+	}
+	return c.Localize(err, lang)
+}
+
+// WithMessageID returns err referring to a message of catalogs with the given ID, which is used by Localize.
+// The message could refer to safeDetails, passed as key/value pairs, using their names in braces.
+// Safe details are added to the details of the error. Other details are never substituted into translated messages,
+// existing details could be marked as safe with WithSafeDetails instead of being passed again.
+// An errkit error is copied with the message ID replaced, the copy still matches err with errors.Is.
+// Any other error is wrapped the same way as WithStack does.
+// Error() still returns the original message. Returns nil when nil is passed.
+//
+//	return errkit.WithMessageID(errkit.New("Restore of PVC failed"), "restore.failed", "pvc", name)
+func WithMessageID(err error, id string, safeDetails ...any) error {
+	return annotate(err, func(e *errkitError) {
+		e.addSafeDetails(ToErrorDetails(safeDetails))
+		e.messageID = id
+	})
+}
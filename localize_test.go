@@ -0,0 +1,210 @@
+package errkit_test
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+//go:embed testdata/locales
+var locales embed.FS
+
+func TestLocalize(t *testing.T) {
+	catalog, err := errkit.LoadCatalog(locales, "testdata/locales/*", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restoreErr := errkit.WithMessageID(errkit.New("Restore failed", "token", "secret"), "restore.failed", "pvc", "data-0")
+
+	t.Run("It should load catalogs from JSON and TOML files", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(catalog.Languages(), qt.DeepEquals, []string{"de", "en", "fr"})
+
+		message, ok := catalog.Message("restore.phase", "de")
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(message, qt.Equals, "Phase {phase} fehlgeschlagen")
+
+		message, ok = catalog.Message("backup.missing", "en")
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(message, qt.Equals, "Backup {id} is missing")
+	})
+
+	t.Run("It should localize messages with safe details as parameters", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.WithMessageID(errkit.Wrap(restoreErr, "Phase failed"), "restore.phase", "phase", "restore")
+
+		c.Assert(catalog.Localize(err, "de"), qt.Equals, "Phase restore fehlgeschlagen: Wiederherstellung von data-0 fehlgeschlagen")
+		c.Assert(catalog.Localize(err, "fr"), qt.Equals, "Phase restore failed: La restauration de data-0 a échoué")
+		c.Assert(err.Error(), qt.Equals, "Phase failed: Restore failed")
+	})
+
+	t.Run("It should not substitute details which are not safe", func(t *testing.T) {
+		c := qt.New(t)
+		catalog := errkit.NewCatalog("")
+		catalog.Add("en", map[string]string{"login.failed": "Login of {user} with {token} failed"})
+
+		err := errkit.WithMessageID(errkit.New("Login failed", "token", "secret"), "login.failed", "user", "admin")
+		c.Assert(catalog.Localize(err, "en"), qt.Equals, "Login of admin with {token} failed")
+
+		err = errkit.WithUserMessage(errkit.WithMessageID(errkit.New("Login failed", "user", "admin"), "login.failed"), "Login failed", "token", "public")
+		c.Assert(catalog.Localize(err, "en"), qt.Equals, "Login of {user} with public failed")
+		c.Assert(catalog.Localize(errkit.WithStack(err), "en"), qt.Equals, "Login of {user} with public failed")
+	})
+
+	t.Run("It should substitute existing details marked as safe", func(t *testing.T) {
+		c := qt.New(t)
+		catalog := errkit.NewCatalog("")
+		catalog.Add("en", map[string]string{"login.failed": "Login of {user} with {token} failed"})
+
+		created := errkit.New("Login failed", "user", "admin", "token", "secret")
+		err := errkit.WithMessageID(errkit.WithSafeDetails(created, "user", "unknown"), "login.failed")
+		c.Assert(catalog.Localize(err, "en"), qt.Equals, "Login of admin with {token} failed")
+		c.Assert(catalog.Localize(errkit.WithMessageID(created, "login.failed"), "en"), qt.Equals, "Login of {user} with {token} failed")
+		c.Assert(errors.Is(err, created), qt.IsTrue)
+		checkErrorResult(t, err, getDetailsCheck(errkit.ErrorDetails{"user": "admin", "token": "secret"}))
+
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(data), qt.Contains, `"safe_details":["user"]`)
+
+		c.Assert(errkit.UserMessage(errkit.WithUserMessage(errkit.WithSafeDetails(created, "user"), "Login of {user} failed")), qt.Equals, "Login of admin failed")
+		c.Assert(errkit.WithSafeDetails(nil, "user"), qt.IsNil)
+	})
+
+	t.Run("It should fall back to the base and default languages", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(catalog.Localize(restoreErr, "de_AT"), qt.Equals, "Wiederherstellung von data-0 fehlgeschlagen")
+		c.Assert(catalog.Localize(restoreErr, "ja"), qt.Equals, "Restore of data-0 failed")
+
+		unknown := errkit.WithMessageID(errkit.New("Lookup failed"), "lookup.failed")
+		c.Assert(catalog.Localize(unknown, "de"), qt.Equals, "Lookup failed")
+	})
+
+	t.Run("It should keep messages of other errors", func(t *testing.T) {
+		c := qt.New(t)
+		list := errkit.Append(restoreErr, errors.New("plain error"))
+		c.Assert(catalog.Localize(list, "de"), qt.Equals, `["Wiederherstellung von data-0 fehlgeschlagen","plain error"]`)
+
+		errorMap := errkit.ErrorMap{"pvc-a": restoreErr}
+		c.Assert(catalog.Localize(errorMap, "de"), qt.Equals, `{"pvc-a":"Wiederherstellung von data-0 fehlgeschlagen"}`)
+		c.Assert(catalog.Localize(nil, "de"), qt.Equals, "")
+
+		setLimits(t, errkit.Limits{MaxListLength: 1})
+		c.Assert(catalog.Localize(list, "de"), qt.Equals, `["Wiederherstellung von data-0 fehlgeschlagen","…(1 more errors truncated)"]`)
+	})
+
+	t.Run("It should localize errors decoded from JSON", func(t *testing.T) {
+		c := qt.New(t)
+		data, err := json.Marshal(errkit.Wrap(restoreErr, "Phase failed"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(data), qt.Contains, `"message_id":"restore.failed"`)
+
+		decoded, err := errkit.UnmarshalErrorFromJSON(data)
+		c.Assert(err, qt.IsNil)
+		c.Assert(catalog.Localize(decoded, "de"), qt.Equals, "Phase failed: Wiederherstellung von data-0 fehlgeschlagen")
+	})
+
+	t.Run("It should use the default catalog", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(errkit.Localize(restoreErr, "de"), qt.Equals, restoreErr.Error())
+
+		errkit.SetDefaultCatalog(catalog)
+		defer errkit.SetDefaultCatalog(nil)
+		c.Assert(errkit.Localize(restoreErr, "de"), qt.Equals, "Wiederherstellung von data-0 fehlgeschlagen")
+	})
+
+	t.Run("It should wrap errors which are not errkit errors", func(t *testing.T) {
+		c := qt.New(t)
+		fnName, lineNumber := getStackInfo()
+		err := errkit.WithMessageID(errPredefinedStdError, "restore.failed")
+		checkErrorResult(t, err, getLocationCheck(fnName, lineNumber+1), getErrkitIsCheck(errPredefinedStdError))
+		c.Assert(errkit.WithMessageID(nil, "restore.failed"), qt.IsNil)
+	})
+
+	t.Run("It should keep identity of annotated errkit errors", func(t *testing.T) {
+		c := qt.New(t)
+		errRestoreFailed := errkit.New("Restore failed")
+		err := errkit.WithMessageID(errRestoreFailed, "restore.failed", "pvc", "data-0")
+
+		c.Assert(errors.Is(err, errRestoreFailed), qt.IsTrue)
+		c.Assert(errors.Is(errkit.Wrap(err, "Phase failed"), errRestoreFailed), qt.IsTrue)
+		c.Assert(errors.Is(errkit.WithUserMessage(err, "Restore failed"), err), qt.IsTrue)
+		c.Assert(catalog.Localize(errkit.WithSeverity(err, errkit.SeverityWarning), "de"), qt.Equals, "Wiederherstellung von data-0 fehlgeschlagen")
+	})
+}
+
+func TestLoadCatalog(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		file    string
+		content string
+		message string
+	}{
+		{
+			name:    "invalid JSON",
+			file:    "en.json",
+			content: `{"restore": `,
+			message: `unable to load catalog en.json: unexpected end of JSON input`,
+		},
+		{
+			name:    "non-string JSON values",
+			file:    "en.json",
+			content: `{"restore": {"retries": 3}}`,
+			message: `unable to load catalog en.json: value of "restore.retries": only string values are supported`,
+		},
+		{
+			name:    "non-string TOML values",
+			file:    "de.toml",
+			content: "[restore]\nretries = 3\n",
+			message: `unable to load catalog de.toml: toml: line 2: value of "retries": only string values are supported`,
+		},
+		{
+			name:    "duplicate TOML keys",
+			file:    "de.toml",
+			content: "a = \"x\"\na = \"y\"\n",
+			message: `unable to load catalog de.toml: toml: line 2: duplicate key "a"`,
+		},
+		{
+			name:    "unterminated TOML strings",
+			file:    "de.toml",
+			content: "a = \"x\n",
+			message: `unable to load catalog de.toml: toml: line 1: value of "a": unterminated string`,
+		},
+	} {
+		t.Run("It should report "+tc.name, func(t *testing.T) {
+			c := qt.New(t)
+			fsys := fstest.MapFS{tc.file: {Data: []byte(tc.content)}}
+			_, err := errkit.LoadCatalog(fsys, "*", "")
+			c.Assert(err, qt.IsNotNil)
+			c.Assert(err.Error(), qt.Equals, tc.message)
+		})
+	}
+
+	t.Run("It should parse TOML strings", func(t *testing.T) {
+		c := qt.New(t)
+		content := "# comment\n" +
+			"plain = \"Tab\\there \\\"quoted\\\" \\u00e9\" # trailing comment\n" +
+			"\"quoted.key\" = 'C:\\path'\n" +
+			"[errors.restore]\n" +
+			"failed = \"Failed # not a comment\"\n"
+		fsys := fstest.MapFS{"en.toml": {Data: []byte(content)}, "README.md": {Data: []byte("ignored")}}
+		catalog, err := errkit.LoadCatalog(fsys, "*", "")
+		c.Assert(err, qt.IsNil)
+
+		for id, expected := range map[string]string{
+			"plain":                 "Tab\there \"quoted\" é",
+			"quoted.key":            `C:\path`,
+			"errors.restore.failed": "Failed # not a comment",
+		} {
+			message, ok := catalog.Message(id, "en")
+			c.Assert(ok, qt.IsTrue, qt.Commentf(id))
+			c.Assert(message, qt.Equals, expected)
+		}
+	})
+}
@@ -1,43 +1,147 @@
 package errkit
 
 import (
+	"bytes"
 	"encoding"
 	"encoding/json"
+	"fmt"
 
 	"github.com/kanisterio/errkit/internal/stack"
 )
 
 type jsonError struct {
-	Message    string       `json:"message,omitempty"`
-	Function   string       `json:"function,omitempty"`
-	LineNumber int          `json:"linenumber,omitempty"`
-	File       string       `json:"file,omitempty"`
-	Details    ErrorDetails `json:"details,omitempty"`
-	Cause      any          `json:"cause,omitempty"`
+	SchemaVersion int          `json:"schema_version,omitempty" description:"Version of the JSON schema, present only on the top level object. Absent in payloads produced before versioning was introduced."`
+	Message       string       `json:"message,omitempty" description:"Message of the error, without messages of its causes."`
+	MessageID     string       `json:"message_id,omitempty" description:"ID of the message in catalogs of translations, when specified."`
+	Function      string       `json:"function,omitempty" description:"Fully qualified name of the function where the error was created."`
+	LineNumber    int          `json:"linenumber,omitempty" description:"Line number where the error was created."`
+	File          string       `json:"file,omitempty" description:"Source file where the error was created."`
+	Details       ErrorDetails `json:"details,omitempty" description:"Details attached to the error."`
+	Payload       any          `json:"payload,omitempty" description:"Typed payload of the error created with NewTyped."`
+	Severity      string       `json:"severity,omitempty" description:"Severity of the error, when specified."`
+	UserMessage   string       `json:"user_message,omitempty" description:"Message which is safe to show to end users, when specified."`
+	SafeDetails   []string     `json:"safe_details,omitempty" description:"Names of details which are safe to show to end users, substituted into translated messages."`
+	Cause         any          `json:"cause,omitempty" description:"Cause of the error. Usually an error or an error list, but errors implementing json.Marshaler could produce any JSON value."`
+	Truncated     bool         `json:"truncated,omitempty" description:"Set when some details or causes of the error were omitted because of size limits."`
+}
+
+var _ error = (*jsonError)(nil)
+var _ json.Marshaler = (*jsonError)(nil)
+
+// Error returns a string representation of the decoded error,
+// in the same format as errkitError does.
+func (e *jsonError) Error() string {
+	cause := e.Unwrap()
+	if cause == nil {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s: %s", e.Message, cause.Error())
+}
+
+// Unwrap returns the decoded cause if it is an error.
+func (e *jsonError) Unwrap() error {
+	switch cause := e.Cause.(type) {
+	case *jsonError:
+		if cause != nil {
+			return cause
+		}
+	case ErrorList:
+		return cause
+	case ErrorMap:
+		return cause
+	}
+	return nil
+}
+
+// Location returns the function, file and line where the decoded error was created.
+func (e *jsonError) Location() (function, file string, line int) {
+	return e.Function, e.File, e.LineNumber
+}
+
+// MarshalJSON encodes the decoded error back to the same representation.
+func (e *jsonError) MarshalJSON() ([]byte, error) {
+	type plain jsonError
+	result := plain(*e)
+	switch cause := result.Cause.(type) {
+	case ErrorList:
+		raw, err := cause.marshalJSON(DefaultLimits(), 0, 1)
+		if err != nil {
+			return nil, err
+		}
+		result.Cause = json.RawMessage(raw)
+	case ErrorMap:
+		raw, err := cause.marshalJSON(DefaultLimits(), 0, 1)
+		if err != nil {
+			return nil, err
+		}
+		result.Cause = json.RawMessage(raw)
+	}
+	return json.Marshal(result)
 }
 
 // UnmarshalJSON return error unmarshaled into jsonError.
 func (e *jsonError) UnmarshalJSON(source []byte) error {
 	var parsedError struct {
-		Message    string          `json:"message,omitempty"`
-		Function   string          `json:"function,omitempty"`
-		LineNumber int             `json:"linenumber,omitempty"`
-		File       string          `json:"file,omitempty"`
-		Details    ErrorDetails    `json:"details,omitempty"`
-		Cause      json.RawMessage `json:"cause,omitempty"`
+		SchemaVersion int             `json:"schema_version,omitempty"`
+		Message       string          `json:"message,omitempty"`
+		MessageID     string          `json:"message_id,omitempty"`
+		Function      string          `json:"function,omitempty"`
+		LineNumber    int             `json:"linenumber,omitempty"`
+		File          string          `json:"file,omitempty"`
+		Details       ErrorDetails    `json:"details,omitempty"`
+		Payload       json.RawMessage `json:"payload,omitempty"`
+		Severity      string          `json:"severity,omitempty"`
+		UserMessage   string          `json:"user_message,omitempty"`
+		SafeDetails   []string        `json:"safe_details,omitempty"`
+		Cause         json.RawMessage `json:"cause,omitempty"`
+		Truncated     bool            `json:"truncated,omitempty"`
 	}
 	err := json.Unmarshal(source, &parsedError)
 	if err != nil {
 		return err
 	}
 
+	if err := checkSchemaVersion(parsedError.SchemaVersion); err != nil {
+		return err
+	}
+
+	e.SchemaVersion = parsedError.SchemaVersion
 	e.Message = parsedError.Message
+	e.MessageID = parsedError.MessageID
 	e.Function = parsedError.Function
 	e.File = parsedError.File
 	e.LineNumber = parsedError.LineNumber
 	e.Details = parsedError.Details
+	if parsedError.Payload != nil {
+		e.Payload = parsedError.Payload
+	}
+	e.Severity = parsedError.Severity
+	e.UserMessage = parsedError.UserMessage
+	e.SafeDetails = parsedError.SafeDetails
+	e.Truncated = parsedError.Truncated
 
-	if parsedError.Cause == nil {
+	if parsedError.Cause == nil || bytes.Equal(parsedError.Cause, []byte("null")) {
+		return nil
+	}
+
+	// Trying to parse as ErrorList
+	if isErrorListJSON(parsedError.Cause) {
+		var list ErrorList
+		if err := json.Unmarshal(parsedError.Cause, &list); err != nil {
+			return err
+		}
+		e.Cause = list
+		return nil
+	}
+
+	// Trying to parse as ErrorMap
+	if isErrorMapJSON(parsedError.Cause) {
+		var errorMap ErrorMap
+		if err := json.Unmarshal(parsedError.Cause, &errorMap); err != nil {
+			return err
+		}
+		e.Cause = errorMap
 		return nil
 	}
 
@@ -71,7 +175,13 @@ func jsonMarshable(err error) any {
 		return err
 	default:
 		// Otherwise wrap the error with {"message":"…"}
-		return jsonError{Message: err.Error()}
+		result := jsonError{Message: err.Error()}
+
+		// Errors which carry their own stack trace (e.g. pkg/errors) keep their location
+		if pcs := importedStack(err); len(pcs) > 0 {
+			result.Function, result.File, result.LineNumber = stack.GetLocationFromStack(pcs, len(pcs))
+		}
+		return result
 	}
 }
 
@@ -80,28 +190,150 @@ func MarshalErrkitErrorToJSON(err *errkitError) ([]byte, error) {
 		return nil, nil
 	}
 
-	function, file, line := stack.GetLocationFromStack(err.stack, err.callers)
+	l := DefaultLimits()
+	raw, e := marshalErrkitError(err, l, SchemaVersion, 0)
+	if e != nil || len(raw) <= l.maxBytes() {
+		return raw, e
+	}
+
+	// Even after applying other limits the error is too big, keeping only its location and message
+	function, file, line := err.Location()
+	return json.Marshal(jsonError{
+		SchemaVersion: SchemaVersion,
+		Message:       truncateText(err.Message(), l.maxBytes()/2),
+		Function:      function,
+		LineNumber:    line,
+		File:          file,
+		Truncated:     true,
+	})
+}
+
+// marshalErrkitError serializes the error nested at the given depth, version is set only on the top level object.
+func marshalErrkitError(err *errkitError, l Limits, version, depth int) ([]byte, error) {
+	function, file, line := err.Location()
 
+	details, detailsTruncated := marshalDetails(err.Details(), l, depth)
 	result := jsonError{
-		Message:    err.Message(),
-		Function:   function,
-		LineNumber: line,
-		File:       file,
-		Details:    err.Details(),
+		SchemaVersion: version,
+		Message:       err.Message(),
+		MessageID:     err.messageID,
+		Function:      function,
+		LineNumber:    line,
+		File:          file,
+		Details:       details,
+		Severity:      err.severity.String(),
+		UserMessage:   err.renderUserMessage(),
+		SafeDetails:   err.safeDetails,
+		Truncated:     detailsTruncated,
+	}
+
+	if err.payload != nil {
+		payload, payloadTruncated := marshalLimitedValue(err.payload, l, depth)
+		result.Payload = payload
+		result.Truncated = result.Truncated || payloadTruncated
 	}
 
 	if err.cause != nil {
-		if kerr, ok := err.cause.(*errkitError); ok {
-			causeJSON, err := MarshalErrkitErrorToJSON(kerr)
-			if err != nil {
-				return nil, err
-			}
-
-			result.Cause = json.RawMessage(causeJSON)
-		} else {
-			result.Cause = jsonMarshable(err.cause)
+		causeJSON, err := marshalNested(err.cause, l, depth+1)
+		if err != nil {
+			return nil, err
 		}
+
+		result.Cause = json.RawMessage(causeJSON)
 	}
 
 	return json.Marshal(result)
 }
+
+// marshalNested serializes an error which is a cause, a member of a list or a detail,
+// so it does not carry the schema version. Errors nested deeper than allowed by limits
+// are replaced with their message marked as truncated.
+func marshalNested(err error, l Limits, depth int) ([]byte, error) {
+	if depth > l.maxCauseDepth() {
+		return json.Marshal(jsonError{Message: shortMessage(err, l), Truncated: true})
+	}
+
+	switch e := err.(type) {
+	case *errkitError:
+		return marshalErrkitError(e, l, 0, depth)
+	case ErrorList:
+		return e.marshalJSON(l, 0, depth)
+	case ErrorMap:
+		return e.marshalJSON(l, 0, depth)
+	}
+
+	raw, e := marshalValue(jsonMarshable(err))
+	if e != nil {
+		// Error can't serialize itself, falling back to its message
+		return json.Marshal(jsonError{Message: err.Error()})
+	}
+	return raw, nil
+}
+
+// UnmarshalErrorFromJSON decodes an error serialized by errkit, either a single error
+// or an ErrorList. Decoded errors keep their messages, locations, details and causes,
+// so serializing them again produces the same JSON.
+// Returns nil when JSON null is passed.
+func UnmarshalErrorFromJSON(data []byte) (error, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	if isErrorListJSON(data) {
+		var list ErrorList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+
+	if isErrorMapJSON(data) {
+		var errorMap ErrorMap
+		if err := json.Unmarshal(data, &errorMap); err != nil {
+			return nil, err
+		}
+		return errorMap, nil
+	}
+
+	switch data[0] {
+	case '{':
+		var e jsonError
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, err
+		}
+		return &e, nil
+	case '"':
+		// Errors implementing encoding.TextMarshaler are serialized as strings
+		var message string
+		if err := json.Unmarshal(data, &message); err != nil {
+			return nil, err
+		}
+		return &jsonError{Message: message}, nil
+	}
+
+	return nil, fmt.Errorf("unable to decode an error from %s", data)
+}
+
+// isErrorListJSON checks whether data has the shape produced by ErrorList.MarshalJSON.
+func isErrorListJSON(data []byte) bool {
+	return errorsJSONKind(data) == '['
+}
+
+// isErrorMapJSON checks whether data has the shape produced by ErrorMap.MarshalJSON.
+func isErrorMapJSON(data []byte) bool {
+	return errorsJSONKind(data) == '{'
+}
+
+// errorsJSONKind returns the first character of the "errors" field,
+// which is an array for error lists and an object for error maps.
+func errorsJSONKind(data []byte) byte {
+	var shape struct {
+		Errors json.RawMessage `json:"errors"`
+	}
+	if json.Unmarshal(data, &shape) != nil || len(shape.Errors) == 0 {
+		return 0
+	}
+
+	return shape.Errors[0]
+}
@@ -0,0 +1,106 @@
+package errkit
+
+import (
+	"errors"
+	"reflect"
+)
+
+// AsAll returns every error of the tree rooted at err which matches type T, in the order of Walk.
+// Errors are matched the same way as errors.As does, so T has to be an interface or a type implementing error.
+// Unlike errors.As, which stops at the first match, all members of error lists are checked.
+func AsAll[T any](err error) []T {
+	var result []T
+	Walk(err, func(err error, _ int, _ []int) WalkAction {
+		if target, ok := asNode[T](err); ok {
+			result = append(result, target)
+		}
+		return WalkContinue
+	})
+	return result
+}
+
+// IsAny reports whether any error of the tree rooted at err matches any of targets, the same way as errors.Is does.
+func IsAny(err error, targets ...error) bool {
+	found := false
+	Walk(err, func(err error, _ int, _ []int) WalkAction {
+		for _, target := range targets {
+			if isNode(err, target) {
+				found = true
+				return WalkStop
+			}
+		}
+		return WalkContinue
+	})
+	return found
+}
+
+// CountIs returns the number of errors of the tree rooted at err which match target, the same way as errors.Is does.
+// Errors wrapping a matching error are not counted, only the matching error itself is.
+func CountIs(err, target error) int {
+	count := 0
+	Walk(err, func(err error, _ int, _ []int) WalkAction {
+		if isNode(err, target) {
+			count++
+		}
+		return WalkContinue
+	})
+	return count
+}
+
+// asNode matches a single error of the tree against type T, without following its branches,
+// which are visited by Walk separately.
+func asNode[T any](err error) (T, bool) {
+	if target, ok := err.(T); ok {
+		return target, true
+	}
+
+	var target T
+	switch e := err.(type) {
+	case ErrorList, ErrorMap:
+		// Members are matched on their own
+	case *errkitError:
+		// The wrapped error is matched on its own, only detail errors are left
+		if e.matchDetails {
+			for _, detail := range e.details.errors() {
+				if errors.As(detail, &target) {
+					return target, true
+				}
+			}
+		}
+	case interface{ As(any) bool }:
+		if e.As(&target) {
+			return target, true
+		}
+	}
+	return target, false
+}
+
+// isNode checks whether a single error of the tree matches target, without following its branches,
+// which are visited by Walk separately.
+func isNode(err, target error) bool {
+	if target == nil {
+		return err == target
+	}
+
+	if reflect.TypeOf(target).Comparable() && err == target {
+		return true
+	}
+
+	switch e := err.(type) {
+	case ErrorList, ErrorMap:
+		// Members are matched on their own
+	case *errkitError:
+		// The wrapped error is matched on its own, only detail errors are left
+		if e.matchDetails {
+			for _, detail := range e.details.errors() {
+				if errors.Is(detail, target) {
+					return true
+				}
+			}
+		}
+		return false
+	case interface{ Is(error) bool }:
+		return e.Is(target)
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package errkit_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func pathError(path string) error {
+	return &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+}
+
+func TestAsAll(t *testing.T) {
+	t.Run("It should collect every matching error of the tree", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(errkit.ErrorList{
+			errkit.Wrap(pathError("/a"), "Failed to restore"),
+			errkit.WithStack(pathError("/b")),
+			errkit.New("Unrelated error"),
+			errors.Join(fmt.Errorf("nested: %w", pathError("/c"))),
+		}, "Restore failed")
+
+		var paths []string
+		for _, e := range errkit.AsAll[*fs.PathError](err) {
+			paths = append(paths, e.Path)
+		}
+		c.Assert(paths, qt.DeepEquals, []string{"/a", "/b", "/c"})
+	})
+
+	t.Run("It should collect errors of trees wrapped by errkit errors once", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.WithStack(errkit.Append(pathError("/a"), pathError("/b")))
+		c.Assert(errkit.AsAll[*fs.PathError](err), qt.HasLen, 2)
+		c.Assert(errkit.CountIs(errkit.WithStack(errkit.Append(errPredefinedSentinelError, errPredefinedSentinelError)), errPredefinedSentinelError), qt.Equals, 2)
+	})
+
+	t.Run("It should return nothing when no error matches", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(errkit.AsAll[*fs.PathError](errkit.New("Some error")), qt.HasLen, 0)
+		c.Assert(errkit.AsAll[*fs.PathError](nil), qt.HasLen, 0)
+	})
+}
+
+func TestIsAny(t *testing.T) {
+	t.Run("It should match any of the targets in the tree", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Append(errkit.New("Some error"), errkit.Wrap(errPredefinedSentinelError, "Wrapped error"))
+
+		c.Assert(errkit.IsAny(err, errPredefinedStdError, errPredefinedSentinelError), qt.IsTrue)
+		c.Assert(errkit.IsAny(err, errPredefinedStdError), qt.IsFalse)
+		c.Assert(errkit.IsAny(err), qt.IsFalse)
+	})
+}
+
+func TestCountIs(t *testing.T) {
+	t.Run("It should count matching errors in the tree", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(errkit.ErrorList{
+			errkit.WithStack(errPredefinedSentinelError),
+			errkit.Wrap(errPredefinedSentinelError, "Wrapped error"),
+			errkit.ErrorList{fmt.Errorf("formatted: %w", errPredefinedSentinelError)},
+			errkit.New("Some error"),
+		}, "Operation failed")
+
+		c.Assert(errkit.CountIs(err, errPredefinedSentinelError), qt.Equals, 3)
+		c.Assert(errkit.CountIs(err, errPredefinedStdError), qt.Equals, 0)
+	})
+}
@@ -0,0 +1,97 @@
+// Package pkgerrors is a compatibility layer which eases migration from
+// github.com/pkg/errors to errkit. It mirrors the most used functions of
+// pkg/errors, while producing errkit errors.
+//
+//	import errors "github.com/kanisterio/errkit/pkgerrors"
+package pkgerrors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kanisterio/errkit"
+	"github.com/kanisterio/errkit/internal/bridge"
+)
+
+type (
+	// Frame is an alias for errkit.Frame.
+	Frame = errkit.Frame
+	// StackTrace is an alias for errkit.StackTrace.
+	StackTrace = errkit.StackTrace
+)
+
+// StackTracer is implemented by errkit errors, the same as errors
+// created by pkg/errors implement `StackTrace() errors.StackTrace`.
+type StackTracer interface {
+	StackTrace() StackTrace
+}
+
+// New returns an error with the supplied message and the stack
+// captured at the point it was called.
+func New(message string) error {
+	return bridge.NewError(errors.New(message), nil)
+}
+
+// Errorf formats according to a format specifier and returns the string
+// as a value that satisfies error.
+// Same as in pkg/errors, `%w` is not supported, use Wrapf instead.
+func Errorf(format string, args ...any) error {
+	return bridge.NewError(errors.New(fmt.Sprintf(format, args...)), nil)
+}
+
+// Wrap returns an error annotating err with a stack trace
+// at the point Wrap is called, and the supplied message.
+// If err is nil, Wrap returns nil.
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	return bridge.NewError(errors.New(message), err)
+}
+
+// Wrapf returns an error annotating err with a stack trace
+// at the point Wrapf is called, and the format specifier.
+// If err is nil, Wrapf returns nil.
+func Wrapf(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	return bridge.NewError(errors.New(fmt.Sprintf(format, args...)), err)
+}
+
+// WithStack annotates err with a stack trace at the point WithStack was called.
+// If err is nil, WithStack returns nil.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return bridge.NewError(err, nil)
+}
+
+// Cause returns the underlying cause of the error, if possible.
+// Unlike pkg/errors, it follows both `Cause() error` and `Unwrap() error`,
+// so chains which mix pkg/errors and errkit errors are fully traversed.
+//
+// If the error does not implement either method, the original error
+// will be returned. If the error is nil, nil will be returned.
+func Cause(err error) error {
+	for err != nil {
+		var next error
+		switch e := err.(type) {
+		case interface{ Cause() error }:
+			next = e.Cause()
+		case interface{ Unwrap() error }:
+			next = e.Unwrap()
+		}
+
+		if next == nil {
+			break
+		}
+		err = next
+	}
+
+	return err
+}
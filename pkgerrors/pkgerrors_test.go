@@ -0,0 +1,155 @@
+package pkgerrors_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+	"github.com/kanisterio/errkit/pkgerrors"
+)
+
+// legacyFrame and legacyError mimic errors produced by github.com/pkg/errors.
+type legacyFrame uintptr
+
+type legacyError struct {
+	msg   string
+	stack []uintptr
+}
+
+func (e *legacyError) Error() string { return e.msg }
+
+func (e *legacyError) StackTrace() []legacyFrame {
+	frames := make([]legacyFrame, len(e.stack))
+	for i, pc := range e.stack {
+		frames[i] = legacyFrame(pc)
+	}
+	return frames
+}
+
+func newLegacyError(msg string) (*legacyError, int) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	_, _, line, _ := runtime.Caller(1)
+	return &legacyError{msg: msg, stack: pcs[:n]}, line
+}
+
+type causer struct {
+	msg   string
+	cause error
+}
+
+func (c *causer) Error() string { return c.msg + ": " + c.cause.Error() }
+func (c *causer) Cause() error  { return c.cause }
+
+func TestCause(t *testing.T) {
+	sentinel := errkit.NewSentinelErr("sentinel")
+
+	t.Run("It should follow both Unwrap and Cause chains", func(t *testing.T) {
+		c := qt.New(t)
+		err := pkgerrors.Wrapf(&causer{msg: "legacy", cause: errkit.Wrap(sentinel, "inner")}, "outer %d", 1)
+		c.Assert(pkgerrors.Cause(err), qt.Equals, sentinel)
+	})
+
+	t.Run("It should return the error itself when there is no cause", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(pkgerrors.Cause(sentinel), qt.Equals, sentinel)
+		c.Assert(pkgerrors.Cause(nil), qt.IsNil)
+	})
+}
+
+func TestWrapfAndErrorf(t *testing.T) {
+
+	t.Run("It should format messages the same way as pkg/errors", func(t *testing.T) {
+		c := qt.New(t)
+		err := pkgerrors.Errorf("resource %q not found", "pvc")
+		c.Assert(err.Error(), qt.Equals, `resource "pvc" not found`)
+
+		wrapped := pkgerrors.Wrapf(err, "unable to restore %s", "app")
+		c.Assert(wrapped.Error(), qt.Equals, `unable to restore app: resource "pvc" not found`)
+		c.Assert(errors.Is(wrapped, err), qt.IsTrue)
+	})
+
+	t.Run("It should return nil when nil is passed", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(pkgerrors.Wrapf(nil, "message %d", 1), qt.IsNil)
+		c.Assert(pkgerrors.Wrap(nil, "message"), qt.IsNil)
+		c.Assert(pkgerrors.WithStack(nil), qt.IsNil)
+	})
+
+	t.Run("It should capture the location of the caller", func(t *testing.T) {
+		c := qt.New(t)
+		_, _, line, _ := runtime.Caller(0)
+		err := pkgerrors.Wrapf(errors.New("cause"), "wrapped")
+		st := err.(pkgerrors.StackTracer).StackTrace()
+		c.Assert(fmt.Sprintf("%n:%d", st[0], st[0]), qt.Equals, fmt.Sprintf("TestWrapfAndErrorf.func3:%d", line+1))
+	})
+}
+
+func TestStackTrace(t *testing.T) {
+
+	t.Run("It should format stack trace like pkg/errors", func(t *testing.T) {
+		c := qt.New(t)
+		err := pkgerrors.New("some error")
+		st := err.(pkgerrors.StackTracer).StackTrace()
+		c.Assert(len(st) > 1, qt.IsTrue)
+		c.Assert(fmt.Sprintf("%s", st[0]), qt.Equals, "pkgerrors_test.go")
+		c.Assert(fmt.Sprintf("%+s", st[0]), qt.Matches, "github.com/kanisterio/errkit/pkgerrors_test.TestStackTrace.func1\n\t.*/pkgerrors_test.go")
+		c.Assert(strings.HasPrefix(fmt.Sprintf("%+v", st), "\ngithub.com/kanisterio/errkit/pkgerrors_test.TestStackTrace.func1\n\t"), qt.IsTrue)
+		c.Assert(fmt.Sprintf("%v", st[:1]), qt.Matches, `\[pkgerrors_test.go:\d+\]`)
+	})
+
+	t.Run("It should print message and stack with %+v", func(t *testing.T) {
+		c := qt.New(t)
+		err := pkgerrors.Wrap(pkgerrors.New("inner"), "outer")
+		out := fmt.Sprintf("%+v", err)
+		c.Assert(out, qt.Matches, "(?s)inner\n.*TestStackTrace.func2\n\t.*outer\n.*TestStackTrace.func2\n\t.*")
+		c.Assert(fmt.Sprintf("%v", err), qt.Equals, "outer: inner")
+	})
+
+	t.Run("It should import frames of a wrapped error which has its own stack trace", func(t *testing.T) {
+		c := qt.New(t)
+		legacy, line := newLegacyError("legacy error")
+		err := errkit.Wrap(legacy, "wrapped")
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+
+		var parsed struct {
+			Cause struct {
+				Message    string `json:"message"`
+				Function   string `json:"function"`
+				LineNumber int    `json:"linenumber"`
+			} `json:"cause"`
+		}
+		c.Assert(json.Unmarshal(data, &parsed), qt.IsNil)
+		c.Assert(parsed.Cause.Message, qt.Equals, "legacy error")
+		c.Assert(parsed.Cause.Function, qt.Equals, "github.com/kanisterio/errkit/pkgerrors_test.TestStackTrace.func3")
+		c.Assert(parsed.Cause.LineNumber, qt.Equals, line)
+	})
+
+	t.Run("It should keep frames of an error with its own stack trace annotated with a stack", func(t *testing.T) {
+		c := qt.New(t)
+		legacy, line := newLegacyError("legacy error")
+		legacyTrace := fmt.Sprintf("%+v", errkit.StackTrace(toFrames(legacy.stack)))
+
+		for _, err := range []error{pkgerrors.WithStack(legacy), errkit.WithStack(legacy), errkit.WithCause(legacy, errors.New("cause"))} {
+			st := err.(pkgerrors.StackTracer).StackTrace()
+			c.Assert(fmt.Sprintf("%+v", st), qt.Equals, legacyTrace)
+			c.Assert(fmt.Sprintf("%n:%d", st[0], st[0]), qt.Equals, fmt.Sprintf("TestStackTrace.func4:%d", line))
+			c.Assert(fmt.Sprintf("%+v", err), qt.Contains, legacyTrace)
+			c.Assert(errors.Is(err, legacy), qt.IsTrue)
+		}
+	})
+}
+
+func toFrames(pcs []uintptr) []errkit.Frame {
+	frames := make([]errkit.Frame, len(pcs))
+	for i, pc := range pcs {
+		frames[i] = errkit.Frame(pc)
+	}
+	return frames
+}
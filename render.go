@@ -0,0 +1,218 @@
+package errkit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ColorMode defines whether Render uses ANSI colors.
+type ColorMode int
+
+const (
+	// ColorAuto enables colors only when the writer is a terminal.
+	ColorAuto ColorMode = iota
+	// ColorAlways enables colors regardless of the writer.
+	ColorAlways
+	// ColorNever disables colors.
+	ColorNever
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiFaint = "\x1b[2m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// RenderOptions controls the output of Render.
+type RenderOptions struct {
+	// MaxDepth is the maximum depth of the rendered tree. Zero means unlimited.
+	MaxDepth int
+	// Color defines whether ANSI colors are used.
+	Color ColorMode
+	// HideLocation hides function, file and line of errors.
+	HideLocation bool
+}
+
+// Located is implemented by errors which know where they were created.
+type Located interface {
+	Location() (function, file string, line int)
+}
+
+// Render writes a human-readable representation of err as a multi-line tree.
+// Causes of errkit errors and members of ErrorList and ErrorMap are drawn as branches,
+// members of ErrorMap are prefixed with their keys.
+// Each node shows the message, sorted details and location of an error.
+//
+//	Unable to restore application
+//	│  namespace=kanister
+//	│  at github.com/kanisterio/kanister/pkg.Restore (pkg/restore.go:42)
+//	└─ 2 errors have occurred
+//	   ├─ Pod not found
+//	   └─ Timeout
+func Render(w io.Writer, err error, opts RenderOptions) error {
+	if err == nil {
+		return nil
+	}
+
+	r := renderer{
+		w:     bufio.NewWriter(w),
+		opts:  opts,
+		color: opts.Color == ColorAlways || (opts.Color == ColorAuto && isTerminal(w)),
+	}
+	r.render(err, "", "", "", 1)
+	return r.w.Flush()
+}
+
+type renderer struct {
+	w     *bufio.Writer
+	opts  RenderOptions
+	color bool
+}
+
+func (r *renderer) render(err error, key, connector, prefix string, depth int) {
+	message, children, keys := renderNode(err)
+	if key != "" {
+		key = r.paint(key, ansiCyan) + ": "
+	}
+	r.line(prefix, connector, key, r.paint(message, ansiBold))
+
+	childPrefix := prefix
+	switch connector {
+	case "":
+	case "└─ ":
+		childPrefix += "   "
+	default:
+		childPrefix += "│  "
+	}
+
+	attrPrefix := childPrefix + "   "
+	if len(children) > 0 {
+		attrPrefix = childPrefix + "│  "
+	}
+
+	if details := DetailsOf(err); len(details) > 0 {
+		for _, k := range details.sortedKeys() {
+			r.line(attrPrefix, r.paint(k, ansiCyan), "=", detailString(details[k]))
+		}
+	}
+
+	if l, ok := err.(Located); ok && !r.opts.HideLocation {
+		if function, file, line := l.Location(); function != "" {
+			r.line(attrPrefix, r.paint(fmt.Sprintf("at %s (%s:%d)", function, file, line), ansiFaint))
+		}
+	}
+
+	if len(children) == 0 {
+		return
+	}
+
+	if r.opts.MaxDepth > 0 && depth >= r.opts.MaxDepth {
+		r.line(childPrefix, "└─ ", r.paint("…", ansiFaint))
+		return
+	}
+
+	for i, child := range children {
+		connector := "├─ "
+		if i == len(children)-1 {
+			connector = "└─ "
+		}
+		var key string
+		if keys != nil {
+			key = keys[i]
+		}
+		r.render(child, key, connector, childPrefix, depth+1)
+	}
+}
+
+func (r *renderer) line(parts ...string) {
+	for _, p := range parts {
+		_, _ = r.w.WriteString(p)
+	}
+	_ = r.w.WriteByte('\n')
+}
+
+func (r *renderer) paint(s, color string) string {
+	if !r.color || s == "" {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// renderNode returns the message of a single node and its branches, along with keys of branches of ErrorMap.
+func renderNode(err error) (string, []error, []string) {
+	switch e := err.(type) {
+	case ErrorList:
+		return listMessage(len(e)), e, nil
+	case ErrorMap:
+		keys := e.keys()
+		children := make([]error, len(keys))
+		for i, k := range keys {
+			children[i] = e[k]
+		}
+		return listMessage(len(keys)), children, keys
+	case warningList:
+		return warningsMessage(len(e)), e, nil
+	case *jsonError:
+		var children []error
+		if cause := e.Unwrap(); cause != nil {
+			children = []error{cause}
+		}
+		return e.Message, children, nil
+	case interface{ Message() string }:
+		var children []error
+		if cause := errors.Unwrap(err); cause != nil {
+			children = []error{cause}
+		}
+		return e.Message(), children, nil
+	case interface{ Unwrap() []error }:
+		children := e.Unwrap()
+		return listMessage(len(children)), children, nil
+	}
+
+	// Message of any other error already includes messages of its causes
+	return strings.TrimSpace(err.Error()), nil, nil
+}
+
+// MessageOf returns the message of err without messages of its causes, the same as Render shows for it.
+// It works for errors decoded from JSON as well. Lists and maps are summarized by the number of their errors.
+func MessageOf(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	message, _, _ := renderNode(err)
+	return message
+}
+
+// DetailsOf returns details of err without details of its causes, including errors decoded from JSON.
+func DetailsOf(err error) ErrorDetails {
+	switch e := err.(type) {
+	case *jsonError:
+		return e.Details
+	case interface{ Details() ErrorDetails }:
+		return e.Details()
+	}
+	return nil
+}
+
+func listMessage(n int) string {
+	if n == 1 {
+		return "1 error has occurred"
+	}
+	return fmt.Sprintf("%d errors have occurred", n)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,130 @@
+package errkit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func TestRender(t *testing.T) {
+	newTree := func() error {
+		list := errkit.Append(
+			errkit.New("Pod not found", "pod", "kanister-pod"),
+			errors.New("timeout"),
+		)
+		inner := errkit.WithCause(errPredefinedSentinelError, list, "phase", "copy", "attempt", 3)
+		return errkit.Wrap(inner, "Unable to restore application", "namespace", "kanister")
+	}
+
+	t.Run("It should render cause chain and error list as a tree", func(t *testing.T) {
+		c := qt.New(t)
+		var buf bytes.Buffer
+		c.Assert(errkit.Render(&buf, newTree(), errkit.RenderOptions{HideLocation: true}), qt.IsNil)
+
+		expected := strings.Join([]string{
+			"Unable to restore application",
+			"│  namespace=kanister",
+			"└─ TEST_ERR: Sample of sentinel error",
+			"   │  attempt=3",
+			"   │  phase=copy",
+			"   └─ 2 errors have occurred",
+			"      ├─ Pod not found",
+			"      │     pod=kanister-pod",
+			"      └─ timeout",
+			"",
+		}, "\n")
+		c.Assert(buf.String(), qt.Equals, expected)
+	})
+
+	t.Run("It should render locations of errors", func(t *testing.T) {
+		c := qt.New(t)
+		fnName, lineNumber := getStackInfo()
+		err := errkit.New("Some error")
+
+		var buf bytes.Buffer
+		c.Assert(errkit.Render(&buf, err, errkit.RenderOptions{}), qt.IsNil)
+		c.Assert(buf.String(), qt.Matches, fmt.Sprintf("Some error\n   at %s \\(.*render_test.go:%d\\)\n", fnName, lineNumber+1))
+	})
+
+	t.Run("It should limit the depth of the tree", func(t *testing.T) {
+		c := qt.New(t)
+		var buf bytes.Buffer
+		c.Assert(errkit.Render(&buf, newTree(), errkit.RenderOptions{HideLocation: true, MaxDepth: 2}), qt.IsNil)
+
+		expected := strings.Join([]string{
+			"Unable to restore application",
+			"│  namespace=kanister",
+			"└─ TEST_ERR: Sample of sentinel error",
+			"   │  attempt=3",
+			"   │  phase=copy",
+			"   └─ …",
+			"",
+		}, "\n")
+		c.Assert(buf.String(), qt.Equals, expected)
+	})
+
+	t.Run("It should use ANSI colors only when requested", func(t *testing.T) {
+		c := qt.New(t)
+		var buf bytes.Buffer
+		c.Assert(errkit.Render(&buf, newTree(), errkit.RenderOptions{}), qt.IsNil)
+		c.Assert(strings.Contains(buf.String(), "\x1b["), qt.IsFalse)
+
+		buf.Reset()
+		c.Assert(errkit.Render(&buf, newTree(), errkit.RenderOptions{Color: errkit.ColorAlways}), qt.IsNil)
+		c.Assert(strings.Contains(buf.String(), "\x1b[1mUnable to restore application\x1b[0m"), qt.IsTrue)
+	})
+
+	t.Run("It should render members of error maps with their keys", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(errkit.ErrorMap{
+			"pvc-b": errors.New("Timeout"),
+			"pvc-a": errkit.New("Snapshot failed", "snapshot", "snap-1"),
+		}, "Backup failed")
+
+		var buf bytes.Buffer
+		c.Assert(errkit.Render(&buf, err, errkit.RenderOptions{HideLocation: true}), qt.IsNil)
+		expected := strings.Join([]string{
+			"Backup failed",
+			"└─ 2 errors have occurred",
+			"   ├─ pvc-a: Snapshot failed",
+			"   │     snapshot=snap-1",
+			"   └─ pvc-b: Timeout",
+			"",
+		}, "\n")
+		c.Assert(buf.String(), qt.Equals, expected)
+
+		buf.Reset()
+		c.Assert(errkit.Render(&buf, err, errkit.RenderOptions{HideLocation: true, Color: errkit.ColorAlways}), qt.IsNil)
+		c.Assert(buf.String(), qt.Contains, "├─ \x1b[36mpvc-a\x1b[0m: \x1b[1mSnapshot failed\x1b[0m")
+	})
+
+	t.Run("It should return messages and details of single errors", func(t *testing.T) {
+		c := qt.New(t)
+		err := newTree()
+		c.Assert(errkit.MessageOf(err), qt.Equals, "Unable to restore application")
+		c.Assert(errkit.DetailsOf(err), qt.DeepEquals, errkit.ErrorDetails{"namespace": "kanister"})
+
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		decoded, e := errkit.UnmarshalErrorFromJSON(data)
+		c.Assert(e, qt.IsNil)
+		c.Assert(errkit.MessageOf(decoded), qt.Equals, "Unable to restore application")
+		c.Assert(errkit.DetailsOf(decoded), qt.DeepEquals, errkit.ErrorDetails{"namespace": "kanister"})
+		c.Assert(errkit.MessageOf(errkit.Unwrap(errkit.Unwrap(decoded))), qt.Equals, "2 errors have occurred")
+		c.Assert(errkit.MessageOf(errors.New("plain")), qt.Equals, "plain")
+		c.Assert(errkit.DetailsOf(errors.New("plain")) == nil, qt.IsTrue)
+	})
+
+	t.Run("It should render branches of joined errors", func(t *testing.T) {
+		c := qt.New(t)
+		var buf bytes.Buffer
+		c.Assert(errkit.Render(&buf, errors.Join(errors.New("first"), errors.New("second")), errkit.RenderOptions{}), qt.IsNil)
+		c.Assert(buf.String(), qt.Equals, "2 errors have occurred\n├─ first\n└─ second\n")
+	})
+}
@@ -0,0 +1,128 @@
+package errkit
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//go:generate go test -run TestJSONSchema -update
+
+// SchemaVersion is the version of the JSON representation of errors produced by errkit.
+// It is written to the top level object as "schema_version" and is incremented
+// only on incompatible changes; adding optional fields does not change it.
+//
+// Payloads without "schema_version" were produced before versioning was introduced,
+// they are treated as version 0 and have the same shape as version 1.
+const SchemaVersion = 1
+
+// JSONSchema is a JSON Schema document describing the JSON representation of errors.
+// It is generated from the Go types used for serialization.
+//
+//go:embed errkit.schema.json
+var JSONSchema []byte
+
+const schemaID = "https://github.com/kanisterio/errkit/errkit.schema.json"
+
+// ErrUnsupportedSchemaVersion is returned when decoding a payload produced by a newer version of errkit.
+var ErrUnsupportedSchemaVersion = NewSentinelErr("unsupported errkit schema version")
+
+func checkSchemaVersion(version int) error {
+	if version < 0 || version > SchemaVersion {
+		return WithStack(ErrUnsupportedSchemaVersion, "version", version, "supported", SchemaVersion)
+	}
+	return nil
+}
+
+// generateJSONSchema produces the JSON Schema document from the types used for serialization.
+func generateJSONSchema() ([]byte, error) {
+	doc := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         schemaID,
+		"title":       "errkit error",
+		"description": fmt.Sprintf("JSON representation of an errkit error, error list or error map, schema version %d.", SchemaVersion),
+		"anyOf": []any{
+			map[string]any{"$ref": "#/$defs/errorList"},
+			map[string]any{"$ref": "#/$defs/errorMap"},
+			map[string]any{"$ref": "#/$defs/error"},
+		},
+		"$defs": map[string]any{
+			"error":     objectSchema(reflect.TypeOf(jsonError{})),
+			"errorList": objectSchema(reflect.TypeOf(jsonErrorList{})),
+			"errorMap":  objectSchema(reflect.TypeOf(jsonErrorMap{})),
+		},
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func objectSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		property := typeSchema(field.Type)
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		switch name {
+		case "schema_version":
+			property["minimum"] = 0
+			property["maximum"] = SchemaVersion
+		case "severity":
+			property["enum"] = severityEnum()
+		}
+		properties[name] = property
+
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	result := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+	return result
+}
+
+func severityEnum() []string {
+	var result []string
+	for s := SeverityDebug; s <= SeverityCritical; s++ {
+		result = append(result, s.String())
+	}
+	return result
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Map:
+		return map[string]any{"type": "object"}
+	case reflect.Slice:
+		if t == reflect.TypeOf(json.RawMessage{}) {
+			return map[string]any{}
+		}
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	}
+
+	// Values of any type
+	return map[string]any{}
+}
@@ -0,0 +1,96 @@
+package errkit
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var update = flag.Bool("update", false, "update the checked in JSON schema")
+
+func TestJSONSchema(t *testing.T) {
+	c := qt.New(t)
+	generated, err := generateJSONSchema()
+	c.Assert(err, qt.IsNil)
+
+	if *update {
+		c.Assert(os.WriteFile("errkit.schema.json", generated, 0o644), qt.IsNil)
+		return
+	}
+
+	c.Assert(string(JSONSchema), qt.Equals, string(generated), qt.Commentf("JSON schema is out of date, run `go generate`"))
+}
+
+func TestSchemaVersion(t *testing.T) {
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]any `json:"properties"`
+			Required   []string       `json:"required"`
+		} `json:"$defs"`
+	}
+	qt.Assert(t, json.Unmarshal(JSONSchema, &schema), qt.IsNil)
+
+	// checkProperties ensures all fields of a serialized object are described by the schema
+	var checkProperties func(c *qt.C, def string, data []byte)
+	checkProperties = func(c *qt.C, def string, data []byte) {
+		var fields map[string]json.RawMessage
+		c.Assert(json.Unmarshal(data, &fields), qt.IsNil)
+		for name := range fields {
+			_, ok := schema.Defs[def].Properties[name]
+			c.Assert(ok, qt.IsTrue, qt.Commentf("field %q is not described in %q", name, def))
+		}
+		for _, name := range schema.Defs[def].Required {
+			_, ok := fields[name]
+			c.Assert(ok, qt.IsTrue, qt.Commentf("required field %q is missing", name))
+		}
+	}
+
+	t.Run("It should write schema version only on the top level object", func(t *testing.T) {
+		c := qt.New(t)
+		err := Wrap(Append(New("First"), New("Second")), "Wrapped", "key", "value")
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		checkProperties(c, "error", data)
+
+		var parsed struct {
+			SchemaVersion int `json:"schema_version"`
+			Cause         struct {
+				SchemaVersion *int              `json:"schema_version"`
+				Errors        []json.RawMessage `json:"errors"`
+			} `json:"cause"`
+		}
+		c.Assert(json.Unmarshal(data, &parsed), qt.IsNil)
+		c.Assert(parsed.SchemaVersion, qt.Equals, SchemaVersion)
+		c.Assert(parsed.Cause.SchemaVersion, qt.IsNil)
+		c.Assert(parsed.Cause.Errors, qt.HasLen, 2)
+		for _, member := range parsed.Cause.Errors {
+			checkProperties(c, "error", member)
+			c.Assert(string(member), qt.Not(qt.Contains), "schema_version")
+		}
+
+		list, e := json.Marshal(Append(New("First"), New("Second")))
+		c.Assert(e, qt.IsNil)
+		checkProperties(c, "errorList", list)
+	})
+
+	t.Run("It should decode payloads of older schema versions", func(t *testing.T) {
+		c := qt.New(t)
+		legacy := `{"message":"Wrapped","function":"main.main","linenumber":10,"file":"main.go","cause":{"message":"2 errors have occurred","errors":[{"message":"First"},{"message":"Second"}]}}`
+		decoded, err := UnmarshalErrorFromJSON([]byte(legacy))
+		c.Assert(err, qt.IsNil)
+		c.Assert(decoded.Error(), qt.Equals, `Wrapped: ["First","Second"]`)
+	})
+
+	t.Run("It should refuse payloads of newer schema versions", func(t *testing.T) {
+		c := qt.New(t)
+		_, err := UnmarshalErrorFromJSON([]byte(`{"schema_version":1000,"message":"From the future"}`))
+		c.Assert(errors.Is(err, ErrUnsupportedSchemaVersion), qt.IsTrue)
+
+		_, err = UnmarshalErrorFromJSON([]byte(`{"schema_version":1000,"message":"2 errors have occurred","errors":[]}`))
+		c.Assert(errors.Is(err, ErrUnsupportedSchemaVersion), qt.IsTrue)
+	})
+}
@@ -0,0 +1,172 @@
+package errkit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Severity tells how serious an error is, e.g. whether it has to page someone
+// or could be logged and ignored. The zero value means the severity is not specified.
+type Severity int
+
+// Severity levels, in increasing order.
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+// LevelCritical is the slog level of critical errors.
+const LevelCritical = slog.LevelError + 4
+
+var severityNames = map[Severity]string{
+	SeverityDebug:    "debug",
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityError:    "error",
+	SeverityCritical: "critical",
+}
+
+// String returns the name of the severity, e.g. "warning", or an empty string when it is not specified.
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	if s == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// ParseSeverity returns the severity with the given name, case insensitive.
+func ParseSeverity(name string) (Severity, error) {
+	for s, n := range severityNames {
+		if strings.EqualFold(n, name) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q", name)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*s = 0
+		return nil
+	}
+
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// Level returns the slog level corresponding to the severity.
+// Errors without specified severity are logged at slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarning:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return LevelCritical
+	}
+	return slog.LevelError
+}
+
+// WithSeverity returns err with the given severity. An errkit error is copied with the severity replaced,
+// the copy still matches err with errors.Is. Any other error is wrapped the same way as WithStack does.
+// Returns nil when nil is passed.
+func WithSeverity(err error, severity Severity) error {
+	return annotate(err, func(e *errkitError) {
+		e.severity = severity
+	})
+}
+
+// SeverityOf returns the highest severity of errors in the tree rooted at err,
+// including causes and members of error lists. Errors could specify their severity
+// with WithSeverity, Builder.Severity or by implementing `Severity() Severity`.
+// A member of a list having no error which specifies the severity counts as SeverityError,
+// so a real failure is never hidden by less severe members of the same list.
+// SeverityError is returned when no error specifies the severity, and 0 for nil.
+func SeverityOf(err error) Severity {
+	if err == nil {
+		return 0
+	}
+
+	if result := treeSeverity(err, map[visit]bool{}); result != 0 {
+		return result
+	}
+	return SeverityError
+}
+
+// treeSeverity returns the highest severity specified in the tree rooted at err, or 0 when none is specified.
+// Errors wrapped by errkit errors and causes belong to the same chain, while members of lists
+// are separate failures, so each of them which specifies no severity counts as SeverityError.
+func treeSeverity(err error, visiting map[visit]bool) Severity {
+	if key, ok := errorIdentity(err); ok {
+		if visiting[key] {
+			return 0
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+	}
+
+	result := errorSeverity(err)
+	list := isList(err)
+	for _, branch := range errorBranches(err) {
+		s := treeSeverity(branch, visiting)
+		if s == 0 && list {
+			s = SeverityError
+		}
+		result = max(result, s)
+	}
+	return result
+}
+
+// isList reports whether branches of err are separate errors, rather than a chain of causes.
+func isList(err error) bool {
+	switch err.(type) {
+	case ErrorList, interface{ Unwrap() []error }:
+		return true
+	}
+	return false
+}
+
+// errorSeverity returns the severity of a single error of a tree.
+func errorSeverity(err error) Severity {
+	switch e := err.(type) {
+	case *errkitError:
+		return e.severity
+	case *jsonError:
+		s, _ := ParseSeverity(e.Severity)
+		return s
+	case interface{ Severity() Severity }:
+		return e.Severity()
+	}
+	return 0
+}
+
+// LogLevel returns the slog level corresponding to the severity of err.
+func LogLevel(err error) slog.Level {
+	return SeverityOf(err).Level()
+}
+
+// Log logs err with logger at the level following the severity of err, the error is added as the "error" attribute.
+func Log(ctx context.Context, logger *slog.Logger, msg string, err error, args ...any) {
+	logger.Log(ctx, LogLevel(err), msg, append(args, "error", err)...)
+}
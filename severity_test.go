@@ -0,0 +1,109 @@
+package errkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+type severeError struct{}
+
+func (severeError) Error() string             { return "severe error" }
+func (severeError) Severity() errkit.Severity { return errkit.SeverityCritical }
+
+func TestSeverity(t *testing.T) {
+	t.Run("It should attach severity to errors", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.New("Volume is skipped")
+		warning := errkit.WithSeverity(err, errkit.SeverityWarning)
+		c.Assert(errkit.SeverityOf(warning), qt.Equals, errkit.SeverityWarning)
+		c.Assert(errkit.SeverityOf(err), qt.Equals, errkit.SeverityError)
+		c.Assert(warning.Error(), qt.Equals, err.Error())
+
+		fnName, lineNumber := getStackInfo()
+		wrapped := errkit.WithSeverity(errPredefinedStdError, errkit.SeverityInfo)
+		checkErrorResult(t, wrapped, getLocationCheck(fnName, lineNumber+1), getErrkitIsCheck(errPredefinedStdError))
+		c.Assert(errkit.SeverityOf(wrapped), qt.Equals, errkit.SeverityInfo)
+		c.Assert(errkit.WithSeverity(nil, errkit.SeverityInfo), qt.IsNil)
+
+		b := errkit.With("component", "controller").Severity(errkit.SeverityCritical)
+		c.Assert(errkit.SeverityOf(b.New("Database is unavailable")), qt.Equals, errkit.SeverityCritical)
+	})
+
+	t.Run("It should keep identity of annotated errkit errors", func(t *testing.T) {
+		c := qt.New(t)
+		errVolumeSkipped := errkit.New("Volume is skipped")
+		warning := errkit.WithSeverity(errVolumeSkipped, errkit.SeverityWarning)
+		c.Assert(errors.Is(warning, errVolumeSkipped), qt.IsTrue)
+		c.Assert(errors.Is(errkit.Wrap(warning, "Backup is incomplete"), errVolumeSkipped), qt.IsTrue)
+
+		info := errkit.WithSeverity(warning, errkit.SeverityInfo)
+		c.Assert(errors.Is(info, warning), qt.IsTrue)
+		c.Assert(errors.Is(info, errVolumeSkipped), qt.IsTrue)
+		c.Assert(errors.Is(errVolumeSkipped, warning), qt.IsFalse)
+		c.Assert(errors.Is(warning, errkit.New("Volume is skipped")), qt.IsFalse)
+	})
+
+	t.Run("It should take the highest severity of the tree", func(t *testing.T) {
+		c := qt.New(t)
+		warning := errkit.WithSeverity(errkit.New("Volume is skipped"), errkit.SeverityWarning)
+		debug := errkit.WithSeverity(errkit.New("Cache miss"), errkit.SeverityDebug)
+
+		c.Assert(errkit.SeverityOf(errkit.Wrap(warning, "Backup completed")), qt.Equals, errkit.SeverityWarning)
+		c.Assert(errkit.SeverityOf(errkit.Append(warning, debug)), qt.Equals, errkit.SeverityWarning)
+		c.Assert(errkit.SeverityOf(errkit.Append(debug, errkit.Wrap(severeError{}, "Failed"))), qt.Equals, errkit.SeverityCritical)
+		c.Assert(errkit.SeverityOf(errkit.WithStack(severeError{})), qt.Equals, errkit.SeverityCritical)
+		c.Assert(errkit.SeverityOf(errkit.Append(errkit.New("Real failure"), debug)), qt.Equals, errkit.SeverityError)
+		c.Assert(errkit.SeverityOf(errkit.Wrap(errkit.Append(warning, errkit.Wrap(errors.New("plain"), "Failed")), "Backup failed")), qt.Equals, errkit.SeverityError)
+		c.Assert(errkit.SeverityOf(errkit.WithSeverity(errkit.Append(errkit.New("Real failure"), debug), errkit.SeverityWarning)), qt.Equals, errkit.SeverityError)
+		c.Assert(errkit.SeverityOf(errkit.ErrorMap{"pvc-a": warning, "pvc-b": debug}), qt.Equals, errkit.SeverityWarning)
+		c.Assert(errkit.SeverityOf(errors.New("plain")), qt.Equals, errkit.SeverityError)
+		c.Assert(errkit.SeverityOf(nil), qt.Equals, errkit.Severity(0))
+	})
+
+	t.Run("It should serialize severity to JSON", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(errkit.WithSeverity(errkit.New("Volume is skipped"), errkit.SeverityWarning), "Backup completed")
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(data), qt.Contains, `"severity":"warning"`)
+
+		decoded, e := errkit.UnmarshalErrorFromJSON(data)
+		c.Assert(e, qt.IsNil)
+		c.Assert(errkit.SeverityOf(decoded), qt.Equals, errkit.SeverityWarning)
+	})
+
+	t.Run("It should parse and format severity names", func(t *testing.T) {
+		c := qt.New(t)
+		for s := errkit.SeverityDebug; s <= errkit.SeverityCritical; s++ {
+			parsed, err := errkit.ParseSeverity(s.String())
+			c.Assert(err, qt.IsNil)
+			c.Assert(parsed, qt.Equals, s)
+		}
+		_, err := errkit.ParseSeverity("fatal")
+		c.Assert(err, qt.ErrorMatches, `unknown severity "fatal"`)
+	})
+
+	t.Run("It should log errors at the level following severity", func(t *testing.T) {
+		c := qt.New(t)
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		errkit.Log(context.Background(), logger, "Backup completed", errkit.WithSeverity(errkit.New("Volume is skipped"), errkit.SeverityWarning))
+		c.Assert(buf.String(), qt.Contains, `"level":"WARN"`)
+		c.Assert(buf.String(), qt.Contains, `"severity":"warning"`)
+
+		buf.Reset()
+		errkit.Log(context.Background(), logger, "Backup failed", errkit.Append(errkit.New("Database is unavailable"), errkit.WithSeverity(errkit.New("Cache miss"), errkit.SeverityDebug)), "attempt", 3)
+		c.Assert(buf.String(), qt.Contains, `"level":"ERROR"`)
+		c.Assert(buf.String(), qt.Contains, `"attempt":3`)
+
+		c.Assert(errkit.LogLevel(severeError{}), qt.Equals, errkit.LevelCritical)
+	})
+}
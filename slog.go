@@ -0,0 +1,107 @@
+package errkit
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+var _ slog.LogValuer = (*errkitError)(nil)
+var _ slog.LogValuer = (ErrorList)(nil)
+var _ slog.LogValuer = (ErrorMap)(nil)
+
+// LogValue implements slog.LogValuer, so errors logged with slog are written
+// as groups with the same fields as the JSON representation. Details are sorted by name.
+func (e *errkitError) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("message", e.Message())}
+	if e.callers > 0 {
+		function, file, line := e.Location()
+		attrs = append(attrs,
+			slog.String("function", function),
+			slog.Int("linenumber", line),
+			slog.String("file", file),
+		)
+	}
+
+	if len(e.details) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "details", Value: e.details.LogValue()})
+	}
+
+	if e.severity != 0 {
+		attrs = append(attrs, slog.String("severity", e.severity.String()))
+	}
+
+	if e.userMessage != "" {
+		attrs = append(attrs, slog.String("user_message", e.renderUserMessage()))
+	}
+
+	if e.messageID != "" {
+		attrs = append(attrs, slog.String("message_id", e.messageID))
+	}
+
+	if len(e.safeDetails) > 0 {
+		attrs = append(attrs, slog.Any("safe_details", e.safeDetails))
+	}
+
+	if e.payload != nil {
+		attrs = append(attrs, slog.Any("payload", e.payload))
+	}
+
+	if e.cause != nil {
+		attrs = append(attrs, errorAttr("cause", e.cause))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer, so the list is logged as a group
+// with the summary message and members keyed by their index.
+func (e ErrorList) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("message", listMessage(len(e))),
+		slog.Attr{Key: "errors", Value: e.membersLogValue()},
+	)
+}
+
+// membersLogValue returns members of the list as a group keyed by their index.
+func (e ErrorList) membersLogValue() slog.Value {
+	members := make([]slog.Attr, 0, len(e))
+	for i, err := range e {
+		members = append(members, errorAttr(strconv.Itoa(i), err))
+	}
+	return slog.GroupValue(members...)
+}
+
+// LogValue implements slog.LogValuer, so the map is logged as a group
+// with the summary message and errors keyed by their items.
+func (e ErrorMap) LogValue() slog.Value {
+	keys := e.keys()
+	members := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		members = append(members, errorAttr(k, e[k]))
+	}
+
+	return slog.GroupValue(
+		slog.String("message", listMessage(len(keys))),
+		slog.Attr{Key: "errors", Value: slog.GroupValue(members...)},
+	)
+}
+
+// LogValue implements slog.LogValuer, so details are logged as a group sorted by name.
+func (d ErrorDetails) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(d))
+	for _, k := range d.sortedKeys() {
+		if err, ok := d[k].(error); ok && err != nil {
+			attrs = append(attrs, errorAttr(k, err))
+			continue
+		}
+		attrs = append(attrs, slog.Any(k, d[k]))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func errorAttr(key string, err error) slog.Attr {
+	if _, ok := err.(slog.LogValuer); ok {
+		return slog.Any(key, err)
+	}
+	return slog.String(key, err.Error())
+}
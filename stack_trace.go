@@ -0,0 +1,210 @@
+package errkit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Frame represents a program counter inside a stack frame.
+// For historical reasons if Frame is interpreted as a uintptr
+// its value represents the program counter + 1, same as in pkg/errors.
+type Frame uintptr
+
+// StackTrace is stack of Frames from innermost (newest) to outermost (oldest).
+type StackTrace []Frame
+
+func (f Frame) location() runtime.Frame {
+	frame, _ := runtime.CallersFrames([]uintptr{uintptr(f)}).Next()
+	return frame
+}
+
+// Format formats the frame according to the fmt.Formatter interface.
+//
+//	%s    source file
+//	%d    source line
+//	%n    function name
+//	%v    equivalent to %s:%d
+//
+// Format accepts flags that alter the printing of some verbs, as follows:
+//
+//	%+s   function name and path of source file relative to the compile time
+//	      GOPATH separated by \n\t (<funcname>\n\t<path>)
+//	%+v   equivalent to %+s:%d
+func (f Frame) Format(s fmt.State, verb rune) {
+	frame := f.location()
+	switch verb {
+	case 's':
+		switch {
+		case s.Flag('+'):
+			fn := frame.Function
+			if fn == "" {
+				fn = "unknown"
+			}
+			_, _ = io.WriteString(s, fn)
+			_, _ = io.WriteString(s, "\n\t")
+			_, _ = io.WriteString(s, frame.File)
+		default:
+			_, _ = io.WriteString(s, path.Base(frame.File))
+		}
+	case 'd':
+		_, _ = io.WriteString(s, strconv.Itoa(frame.Line))
+	case 'n':
+		_, _ = io.WriteString(s, funcname(frame.Function))
+	case 'v':
+		f.Format(s, 's')
+		_, _ = io.WriteString(s, ":")
+		f.Format(s, 'd')
+	}
+}
+
+// MarshalText formats a stacktrace Frame as a text string. The output is the
+// same as that of fmt.Sprintf("%+v", f), but without newlines or tabs.
+func (f Frame) MarshalText() ([]byte, error) {
+	frame := f.location()
+	if frame.Function == "" {
+		return []byte("unknown"), nil
+	}
+	return []byte(fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line)), nil
+}
+
+// Format formats the stack of Frames according to the fmt.Formatter interface.
+//
+//	%s	lists source files for each Frame in the stack
+//	%v	lists the source file and line number for each Frame in the stack
+//
+// Format accepts flags that alter the printing of some verbs, as follows:
+//
+//	%+v   Prints filename, function, and line number for each Frame in the stack.
+func (st StackTrace) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			for _, f := range st {
+				_, _ = io.WriteString(s, "\n")
+				f.Format(s, verb)
+			}
+		case s.Flag('#'):
+			fmt.Fprintf(s, "%#v", []Frame(st))
+		default:
+			st.formatSlice(s, verb)
+		}
+	case 's':
+		st.formatSlice(s, verb)
+	}
+}
+
+// formatSlice will format this StackTrace into the given buffer as a slice of
+// Frame, only valid when called with '%s' or '%v'.
+func (st StackTrace) formatSlice(s fmt.State, verb rune) {
+	_, _ = io.WriteString(s, "[")
+	for i, f := range st {
+		if i > 0 {
+			_, _ = io.WriteString(s, " ")
+		}
+		f.Format(s, verb)
+	}
+	_, _ = io.WriteString(s, "]")
+}
+
+// funcname removes the path prefix component of a function's name reported by func.Name().
+func funcname(name string) string {
+	i := strings.LastIndex(name, "/")
+	name = name[i+1:]
+	i = strings.Index(name, ".")
+	return name[i+1:]
+}
+
+func toStackTrace(pcs []uintptr) StackTrace {
+	st := make(StackTrace, len(pcs))
+	for i, pc := range pcs {
+		st[i] = Frame(pc)
+	}
+	return st
+}
+
+// importedStack returns the program counters of an error which is not an errkit error,
+// but carries its own stack trace, e.g. errors created by pkg/errors.
+// Such errors implement `StackTrace() T` where T is a slice of uintptr based frames.
+func importedStack(err error) []uintptr {
+	switch e := err.(type) {
+	case nil, *errkitError:
+		return nil
+	case interface{ StackTrace() StackTrace }:
+		st := e.StackTrace()
+		pcs := make([]uintptr, len(st))
+		for i, f := range st {
+			pcs[i] = uintptr(f)
+		}
+		return pcs
+	}
+
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() {
+		return nil
+	}
+
+	t := method.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	frames := method.Call(nil)[0]
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return pcs
+}
+
+// causeStack returns the stack of the closest error in the chain which has one.
+func causeStack(err error) []uintptr {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*errkitError); ok {
+			if e.callers > 0 {
+				return e.stack
+			}
+			continue
+		}
+		if pcs := importedStack(err); pcs != nil {
+			return pcs
+		}
+	}
+	return nil
+}
+
+// sharedFrames are the outer frames of a stack which are also present in the stack of its cause.
+// They start at start in the stack and at causeStart in the stack of the cause.
+type sharedFrames struct {
+	start, causeStart, length int
+}
+
+// repeatedFrames finds the outer frames of stack which are also present in the stack of the cause.
+// Frames of the cause stack are aligned with stack, so that the common part reaches the end of either of them,
+// since any of the stacks could be truncated. The first frame is never considered repeated,
+// as it is the location of the error.
+func repeatedFrames(stack, cause []uintptr) sharedFrames {
+	for i := 1; i < len(stack); i++ {
+		for j := range cause {
+			if stack[i] != cause[j] {
+				continue
+			}
+
+			n := 1
+			for i+n < len(stack) && j+n < len(cause) && stack[i+n] == cause[j+n] {
+				n++
+			}
+			// A single common frame is the entry of different goroutines, e.g. runtime.goexit
+			if n > 1 && (i+n == len(stack) || j+n == len(cause)) {
+				return sharedFrames{start: i, causeStart: j, length: n}
+			}
+		}
+	}
+	return sharedFrames{}
+}
@@ -0,0 +1,83 @@
+package errkit_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func createRootCause() error {
+	return errkit.New("Root cause")
+}
+
+func wrapRootCause() error {
+	return errkit.Wrap(createRootCause(), "Middle error")
+}
+
+func TestStackFormatting(t *testing.T) {
+	t.Run("It should not repeat frames of the cause when printing the chain", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(wrapRootCause(), "Top error")
+		out := fmt.Sprintf("%+v", err)
+
+		c.Assert(strings.Count(out, "testing.tRunner"), qt.Equals, 1)
+		c.Assert(strings.Count(out, "errkit_test.TestStackFormatting.func1\n"), qt.Equals, 2)
+		c.Assert(out, qt.Matches, `(?s)Root cause\n.*createRootCause\n.*wrapRootCause\n.*`+
+			`Middle error\n.*wrapRootCause\n\t[^\n]*\n\[\.\.\.\d+ frames repeated above\.\.\.\]\n`+
+			`Top error\n.*TestStackFormatting.func1\n\t[^\n]*\n\[\.\.\.\d+ frames repeated above\.\.\.\]`)
+	})
+
+	t.Run("It should keep the full stack trace of each error", func(t *testing.T) {
+		c := qt.New(t)
+		err := wrapRootCause()
+
+		var st interface{ StackTrace() errkit.StackTrace }
+		c.Assert(errkit.As(err, &st), qt.IsTrue)
+		c.Assert(fmt.Sprintf("%+v", st.StackTrace()), qt.Contains, "testing.tRunner")
+	})
+
+	t.Run("It should restore frames shared with the cause in the stack trace", func(t *testing.T) {
+		c := qt.New(t)
+		names := func(err error) []string {
+			st := err.(interface{ StackTrace() errkit.StackTrace }).StackTrace()
+			result := make([]string, len(st))
+			for i, f := range st {
+				result[i] = fmt.Sprintf("%n", f)
+			}
+			return result
+		}
+
+		root := createRootCause()
+		middle := errkit.Wrap(root, "Middle error")
+		top := errkit.Wrap(middle, "Top error")
+
+		c.Assert(names(root)[0], qt.Equals, "createRootCause")
+		c.Assert(names(middle), qt.DeepEquals, names(root)[1:])
+		c.Assert(names(top), qt.DeepEquals, names(middle))
+	})
+
+	t.Run("It should print the whole stack when the cause was created in another goroutine", func(t *testing.T) {
+		c := qt.New(t)
+		causes := make(chan error)
+		go func() {
+			causes <- createRootCause()
+		}()
+
+		err := errkit.Wrap(<-causes, "Top error")
+		out := fmt.Sprintf("%+v", err)
+		c.Assert(out, qt.Not(qt.Contains), "repeated above")
+		c.Assert(strings.Count(out, "testing.tRunner"), qt.Equals, 1)
+	})
+
+	t.Run("It should print the whole stack when the cause does not print the stack of its own cause", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(fmt.Errorf("context: %w", createRootCause()), "Top error")
+		out := fmt.Sprintf("%+v", err)
+		c.Assert(out, qt.Not(qt.Contains), "repeated above")
+		c.Assert(strings.Count(out, "testing.tRunner"), qt.Equals, 1)
+		c.Assert(out, qt.Contains, "runtime.goexit")
+	})
+}
@@ -0,0 +1,68 @@
+package errkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// NewTyped returns an error with the given message carrying a typed payload,
+// which could be retrieved with Payload, e.g.:
+//
+//	return errkit.NewTyped("Resources are in conflict", conflictingNames, "namespace", ns)
+//	...
+//	if names, ok := errkit.Payload[[]string](err); ok {
+//	    ...
+//	}
+//
+// The payload is serialized to JSON as the "payload" field.
+func NewTyped[T any](message string, payload T, details ...any) error {
+	e := newErrorWithConfig(DefaultConfig(), nil, message, nil, 2, ToErrorDetails(details))
+	e.payload = payload
+	return e
+}
+
+// Payload returns the payload of the first error in the chain of causes having a payload of type T.
+// Payloads of errors decoded from JSON are decoded into T. Such a payload matches T only when
+// all of its fields are known to T, but fields of T missing from the payload are left empty.
+func Payload[T any](err error) (T, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch e := err.(type) {
+		case *errkitError:
+			if payload, ok := e.payload.(T); ok {
+				return payload, true
+			}
+		case *jsonError:
+			if raw, ok := e.Payload.(json.RawMessage); ok {
+				if payload, ok := decodePayload[T](raw); ok {
+					return payload, true
+				}
+			}
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// decodePayload decodes a serialized payload into T, rejecting payloads which have fields unknown to T.
+func decodePayload[T any](raw json.RawMessage) (T, bool) {
+	var payload T
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if dec.Decode(&payload) != nil {
+		return payload, false
+	}
+	return payload, true
+}
+
+// AsType finds the first error in the chain that matches type T, and if so, returns it.
+// It is a shorthand for:
+//
+//	var target T
+//	ok := errkit.As(err, &target)
+func AsType[T error](err error) (T, bool) {
+	var target T
+	ok := errors.As(err, &target)
+	return target, ok
+}
@@ -0,0 +1,94 @@
+package errkit_test
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+type conflict struct {
+	Names []string `json:"names"`
+}
+
+func TestTypedErrors(t *testing.T) {
+	t.Run("It should create an error with a payload", func(t *testing.T) {
+		c := qt.New(t)
+		fnName, lineNumber := getStackInfo()
+		err := errkit.NewTyped("Resources are in conflict", conflict{Names: []string{"a", "b"}}, "namespace", "default")
+		checkErrorResult(t, err,
+			getMessageCheck("Resources are in conflict"),
+			getLocationCheck(fnName, lineNumber+1),
+			getDetailsCheck(errkit.ErrorDetails{"namespace": "default"}),
+		)
+
+		payload, ok := errkit.Payload[conflict](err)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(payload.Names, qt.DeepEquals, []string{"a", "b"})
+	})
+
+	t.Run("It should find a payload in the chain of causes", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(errkit.NewTyped("Resources are in conflict", []string{"a"}), "Failed to restore")
+
+		names, ok := errkit.Payload[[]string](err)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(names, qt.DeepEquals, []string{"a"})
+
+		_, ok = errkit.Payload[conflict](err)
+		c.Assert(ok, qt.IsFalse)
+		_, ok = errkit.Payload[[]string](errkit.New("No payload"))
+		c.Assert(ok, qt.IsFalse)
+	})
+
+	t.Run("It should serialize the payload and decode it back", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(errkit.NewTyped("Resources are in conflict", conflict{Names: []string{"a"}}), "Failed to restore")
+
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(data), qt.Contains, `"payload":{"names":["a"]}`)
+
+		decoded, e := errkit.UnmarshalErrorFromJSON(data)
+		c.Assert(e, qt.IsNil)
+		payload, ok := errkit.Payload[conflict](decoded)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(payload.Names, qt.DeepEquals, []string{"a"})
+	})
+
+	t.Run("It should not decode a payload of another type", func(t *testing.T) {
+		c := qt.New(t)
+		type quota struct {
+			Limit int `json:"limit"`
+		}
+		data, e := json.Marshal(errkit.NewTyped("Quota exceeded", quota{Limit: 10}))
+		c.Assert(e, qt.IsNil)
+
+		decoded, e := errkit.UnmarshalErrorFromJSON(data)
+		c.Assert(e, qt.IsNil)
+		_, ok := errkit.Payload[conflict](decoded)
+		c.Assert(ok, qt.IsFalse)
+		_, ok = errkit.Payload[[]string](decoded)
+		c.Assert(ok, qt.IsFalse)
+
+		payload, ok := errkit.Payload[quota](decoded)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(payload.Limit, qt.Equals, 10)
+	})
+
+	t.Run("It should find an error of the given type", func(t *testing.T) {
+		c := qt.New(t)
+		_, cause := os.Open("/nonexistent")
+		err := errkit.Wrap(cause, "Failed to open")
+
+		pathErr, ok := errkit.AsType[*fs.PathError](err)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(pathErr.Path, qt.Equals, "/nonexistent")
+
+		_, ok = errkit.AsType[*json.SyntaxError](err)
+		c.Assert(ok, qt.IsFalse)
+	})
+}
@@ -0,0 +1,124 @@
+package errkit
+
+import (
+	"slices"
+	"strings"
+)
+
+// GenericUserMessage is returned by UserMessage for errors which have no user-facing message.
+const GenericUserMessage = "An unexpected error has occurred"
+
+// WithUserMessage returns err with a message which is safe to show to end users,
+// separate from the message returned by Error(), which is intended for developers.
+// The message could refer to safeDetails, passed as key/value pairs, using their names in braces:
+//
+//	return errkit.WithUserMessage(err, "Volume {pvc} could not be restored", "pvc", pvc.Name)
+//
+// Safe details are added to the details of the error, along with safe details passed to WithMessageID.
+// Other details are never included in the user-facing message, unless they are marked with WithSafeDetails.
+// An errkit error is copied with the user message
+// replaced, the copy still matches err with errors.Is. Any other error is wrapped the same way as WithStack does.
+// Returns nil when nil is passed.
+func WithUserMessage(err error, message string, safeDetails ...any) error {
+	return annotate(err, func(e *errkitError) {
+		e.addSafeDetails(ToErrorDetails(safeDetails))
+		e.userMessage = message
+	})
+}
+
+// WithSafeDetails returns err with its existing details of the given names marked as safe to show to end users,
+// so they are substituted into user-facing and translated messages the same way as safe details passed
+// to WithUserMessage or WithMessageID. It is useful when details were added where the error was created:
+//
+//	err := errkit.New("Restore failed", "pvc", pvc.Name, "token", token)
+//	...
+//	return errkit.WithMessageID(errkit.WithSafeDetails(err, "pvc"), "restore.failed")
+//
+// Only details of err itself are marked, names it has no details for are ignored. An errkit error is copied,
+// the copy still matches err with errors.Is. Any other error is wrapped the same way as WithStack does,
+// so it has no details to mark. Returns nil when nil is passed.
+func WithSafeDetails(err error, names ...string) error {
+	return annotate(err, func(e *errkitError) {
+		known := make([]string, 0, len(names))
+		for _, name := range names {
+			if _, ok := e.details[name]; ok {
+				known = append(known, name)
+			}
+		}
+		e.markSafe(known)
+	})
+}
+
+// addSafeDetails adds details which are safe to show to end users to the details of the error.
+func (e *errkitError) addSafeDetails(safe ErrorDetails) {
+	if len(safe) == 0 {
+		return
+	}
+
+	e.details = mergeDetails(e.details, safe)
+	e.markSafe(safe.sortedKeys())
+}
+
+// markSafe adds names of details which are safe to show to end users.
+func (e *errkitError) markSafe(names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	keys := append(append([]string(nil), e.safeDetails...), names...)
+	slices.Sort(keys)
+	e.safeDetails = slices.Compact(keys)
+}
+
+// UserMessage returns the user-facing message of the nearest error in the tree rooted at err,
+// which has one, with safe details substituted. Messages of other errors, their details and causes are not included.
+// GenericUserMessage is returned when no error has a user-facing message, and an empty string for nil.
+func UserMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	message := GenericUserMessage
+	Walk(err, func(err error, _ int, _ []int) WalkAction {
+		if m, ok := errorUserMessage(err); ok {
+			message = m
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	return message
+}
+
+// errorUserMessage returns the user-facing message of a single error of a tree.
+func errorUserMessage(err error) (string, bool) {
+	switch e := err.(type) {
+	case *errkitError:
+		if e.userMessage == "" {
+			return "", false
+		}
+		return e.renderUserMessage(), true
+	case *jsonError:
+		return e.UserMessage, e.UserMessage != ""
+	}
+	return "", false
+}
+
+// renderUserMessage substitutes safe details into the user-facing message.
+func (e *errkitError) renderUserMessage() string {
+	return substituteSafeDetails(e.userMessage, e.details, e.safeDetails)
+}
+
+// substituteSafeDetails replaces names of safe details in braces with their values, other details are left as they are.
+func substituteSafeDetails(message string, details ErrorDetails, safe []string) string {
+	if len(safe) == 0 || !strings.Contains(message, "{") {
+		return message
+	}
+
+	replacements := make([]string, 0, 2*len(safe))
+	for _, k := range safe {
+		if v, ok := details[k]; ok {
+			replacements = append(replacements, "{"+k+"}", detailString(v))
+		}
+	}
+	return strings.NewReplacer(replacements...).Replace(message)
+}
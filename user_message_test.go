@@ -0,0 +1,81 @@
+package errkit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func TestUserMessage(t *testing.T) {
+	t.Run("It should keep the user message separate from the developer message", func(t *testing.T) {
+		c := qt.New(t)
+		cause := errkit.New("dial tcp 10.0.0.1:5432: connection refused", "password", "secret")
+		err := errkit.WithUserMessage(errkit.Wrap(cause, "Unable to query catalog"), "Backup catalog is unavailable")
+
+		c.Assert(err.Error(), qt.Equals, "Unable to query catalog: dial tcp 10.0.0.1:5432: connection refused")
+		c.Assert(errkit.UserMessage(err), qt.Equals, "Backup catalog is unavailable")
+		c.Assert(errkit.Is(err, cause), qt.IsTrue)
+	})
+
+	t.Run("It should keep identity of annotated errkit errors", func(t *testing.T) {
+		c := qt.New(t)
+		errCatalogUnavailable := errkit.New("Unable to query catalog")
+		err := errkit.WithUserMessage(errCatalogUnavailable, "Backup catalog is unavailable")
+
+		c.Assert(errors.Is(err, errCatalogUnavailable), qt.IsTrue)
+		c.Assert(errors.Is(errkit.Wrap(err, "Backup failed"), errCatalogUnavailable), qt.IsTrue)
+		c.Assert(errors.Is(errkit.WithSeverity(err, errkit.SeverityWarning), err), qt.IsTrue)
+		c.Assert(errors.Is(errCatalogUnavailable, err), qt.IsFalse)
+	})
+
+	t.Run("It should find the nearest user message in the chain", func(t *testing.T) {
+		c := qt.New(t)
+		inner := errkit.WithUserMessage(errkit.New("PVC lookup failed"), "Volume could not be found")
+		outer := errkit.WithUserMessage(errkit.Wrap(inner, "Restore failed"), "Restore could not be completed")
+
+		c.Assert(errkit.UserMessage(errkit.Wrap(inner, "Restore failed")), qt.Equals, "Volume could not be found")
+		c.Assert(errkit.UserMessage(outer), qt.Equals, "Restore could not be completed")
+		c.Assert(errkit.UserMessage(errkit.Append(errkit.New("Internal"), inner)), qt.Equals, "Volume could not be found")
+		c.Assert(errkit.UserMessage(errkit.WithStack(inner)), qt.Equals, "Volume could not be found")
+	})
+
+	t.Run("It should fall back to a generic message", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(errkit.UserMessage(errkit.New("Internal failure", "token", "secret")), qt.Equals, errkit.GenericUserMessage)
+		c.Assert(errkit.UserMessage(errors.New("plain")), qt.Equals, errkit.GenericUserMessage)
+		c.Assert(errkit.UserMessage(nil), qt.Equals, "")
+	})
+
+	t.Run("It should substitute only safe details", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.New("Restore failed", "namespace", "prod", "token", "secret")
+		err = errkit.WithUserMessage(err, "Volume {pvc} could not be restored, {token}", "pvc", "data-0")
+
+		c.Assert(errkit.UserMessage(err), qt.Equals, "Volume data-0 could not be restored, {token}")
+		checkErrorResult(t, err, getDetailsCheck(errkit.ErrorDetails{"namespace": "prod", "token": "secret", "pvc": "data-0"}))
+	})
+
+	t.Run("It should wrap errors which are not errkit errors", func(t *testing.T) {
+		c := qt.New(t)
+		fnName, lineNumber := getStackInfo()
+		err := errkit.WithUserMessage(errPredefinedStdError, "Something went wrong")
+		checkErrorResult(t, err, getLocationCheck(fnName, lineNumber+1), getErrkitIsCheck(errPredefinedStdError))
+		c.Assert(errkit.UserMessage(err), qt.Equals, "Something went wrong")
+		c.Assert(errkit.WithUserMessage(nil, "Something went wrong"), qt.IsNil)
+	})
+
+	t.Run("It should serialize the user message and decode it back", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.Wrap(errkit.WithUserMessage(errkit.New("Lookup failed"), "Volume {pvc} is missing", "pvc", "data-0"), "Restore failed")
+		data, e := json.Marshal(err)
+		c.Assert(e, qt.IsNil)
+		c.Assert(string(data), qt.Contains, `"user_message":"Volume data-0 is missing"`)
+
+		decoded, e := errkit.UnmarshalErrorFromJSON(data)
+		c.Assert(e, qt.IsNil)
+		c.Assert(errkit.UserMessage(decoded), qt.Equals, "Volume data-0 is missing")
+	})
+}
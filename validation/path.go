@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Path represents the path from the root of a validated object to one of its fields,
+// e.g. spec.phases[2].args[namespace]. Paths are immutable, methods return new paths
+// sharing their parents, so a common prefix could be reused for many fields:
+//
+//	spec := validation.NewPath("spec")
+//	errs = append(errs, validation.Required(spec.Child("blueprint"), ""))
+//	errs = append(errs, validation.Invalid(spec.Child("phases").Index(2).Key("x"), value, "must be a string"))
+type Path struct {
+	// name is the name of a field, or the index or the key in square brackets.
+	name   string
+	parent *Path
+}
+
+// NewPath returns a path with the given root field name followed by moreNames.
+func NewPath(name string, moreNames ...string) *Path {
+	var root *Path
+	return root.Child(name, moreNames...)
+}
+
+// Child returns the path to a field with the given name, followed by moreNames.
+func (p *Path) Child(name string, moreNames ...string) *Path {
+	result := &Path{name: name, parent: p}
+	for _, n := range moreNames {
+		result = &Path{name: n, parent: result}
+	}
+	return result
+}
+
+// Index returns the path to an element of a list.
+func (p *Path) Index(index int) *Path {
+	return &Path{name: "[" + strconv.Itoa(index) + "]", parent: p}
+}
+
+// Key returns the path to an element of a map.
+func (p *Path) Key(key string) *Path {
+	return &Path{name: "[" + key + "]", parent: p}
+}
+
+// Root returns the first element of the path.
+func (p *Path) Root() *Path {
+	for p.parent != nil {
+		p = p.parent
+	}
+	return p
+}
+
+// String returns the path in the dotted notation, e.g. spec.phases[2].args[namespace].
+func (p *Path) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+
+	var elems []string
+	for e := p; e != nil; e = e.parent {
+		elems = append(elems, e.name)
+	}
+
+	var sb strings.Builder
+	for i := len(elems) - 1; i >= 0; i-- {
+		if i < len(elems)-1 && !strings.HasPrefix(elems[i], "[") {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(elems[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+// Package validation creates errkit errors for fields of validated objects,
+// e.g. specs of custom resources. Each error has the path to the field
+// and the bad value as details, so errors collected into errkit.ErrorList
+// are reported with their fields both in text and in JSON:
+//
+//	var errs errkit.ErrorList
+//	spec := validation.NewPath("spec")
+//	if as.Spec.Blueprint == "" {
+//	    errs = append(errs, validation.Required(spec.Child("blueprint"), "blueprint name has to be set"))
+//	}
+//	...
+//	return errs.Err()
+package validation
+
+import (
+	"fmt"
+
+	"github.com/kanisterio/errkit"
+	"github.com/kanisterio/errkit/internal/bridge"
+)
+
+// Details of validation errors.
+const (
+	// FieldKey is the detail holding the path to the field.
+	FieldKey = "field"
+	// ValueKey is the detail holding the bad value.
+	ValueKey = "value"
+	// SupportedKey is the detail holding supported values of NotSupported errors.
+	SupportedKey = "supported"
+	// MaxLengthKey is the detail holding the maximum length of TooLong errors.
+	MaxLengthKey = "max_length"
+)
+
+// Sentinel errors for types of validation errors, to be matched with errkit.Is.
+var (
+	ErrRequired     = errkit.NewSentinelErr("Required value")
+	ErrInvalid      = errkit.NewSentinelErr("Invalid value")
+	ErrNotSupported = errkit.NewSentinelErr("Unsupported value")
+	ErrDuplicate    = errkit.NewSentinelErr("Duplicate value")
+	ErrTooLong      = errkit.NewSentinelErr("Too long")
+)
+
+// Required returns an error indicating that a required field is not set.
+// detail is an optional explanation.
+func Required(path *Path, detail string) error {
+	return bridge.NewError(fieldError(ErrRequired, path, detail), nil, FieldKey, path.String())
+}
+
+// Invalid returns an error indicating that the field has an invalid value,
+// detail explains why the value is invalid.
+func Invalid(path *Path, value any, detail string) error {
+	return bridge.NewError(fieldError(ErrInvalid, path, detail), nil, FieldKey, path.String(), ValueKey, value)
+}
+
+// NotSupported returns an error indicating that the field has a value which is not one of supported values.
+func NotSupported(path *Path, value any, supported []string) error {
+	return bridge.NewError(fieldError(ErrNotSupported, path, ""), nil, FieldKey, path.String(), ValueKey, value, SupportedKey, supported)
+}
+
+// Duplicate returns an error indicating that the value of the field is a duplicate of another value,
+// e.g. an element of a list which has to be unique.
+func Duplicate(path *Path, value any) error {
+	return bridge.NewError(fieldError(ErrDuplicate, path, ""), nil, FieldKey, path.String(), ValueKey, value)
+}
+
+// TooLong returns an error indicating that the value of the field is longer than maxLength.
+func TooLong(path *Path, value any, maxLength int) error {
+	detail := fmt.Sprintf("may not be longer than %d", maxLength)
+	return bridge.NewError(fieldError(ErrTooLong, path, detail), nil, FieldKey, path.String(), ValueKey, value, MaxLengthKey, maxLength)
+}
+
+// fieldError returns the error for the given type, which has the path to the field in its message.
+// Constructors call bridge.NewError directly, so the stack is captured at their callers.
+func fieldError(errType error, path *Path, detail string) error {
+	if detail == "" {
+		return fmt.Errorf("%s: %w", path, errType)
+	}
+	return fmt.Errorf("%s: %w: %s", path, errType, detail)
+}
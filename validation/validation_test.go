@@ -0,0 +1,127 @@
+package validation_test
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+	"github.com/kanisterio/errkit/validation"
+)
+
+func TestPath(t *testing.T) {
+	t.Run("It should format paths in dotted notation", func(t *testing.T) {
+		c := qt.New(t)
+		spec := validation.NewPath("spec")
+		c.Assert(spec.String(), qt.Equals, "spec")
+		c.Assert(spec.Child("phases").Index(2).Key("x").String(), qt.Equals, "spec.phases[2][x]")
+		c.Assert(validation.NewPath("metadata", "labels").Key("app").Child("name").String(), qt.Equals, "metadata.labels[app].name")
+		c.Assert(spec.Child("phases").Index(0).Root(), qt.Equals, spec)
+	})
+
+	t.Run("It should not modify parent paths", func(t *testing.T) {
+		c := qt.New(t)
+		phases := validation.NewPath("spec").Child("phases")
+		first := phases.Index(0)
+		second := phases.Index(1)
+		c.Assert(first.String(), qt.Equals, "spec.phases[0]")
+		c.Assert(second.String(), qt.Equals, "spec.phases[1]")
+		c.Assert(phases.String(), qt.Equals, "spec.phases")
+	})
+}
+
+func TestErrors(t *testing.T) {
+	path := validation.NewPath("spec").Child("phases").Index(1).Child("name")
+	for _, tc := range []struct {
+		name    string
+		err     error
+		errType error
+		message string
+		details errkit.ErrorDetails
+	}{
+		{
+			name:    "Required",
+			err:     validation.Required(path, ""),
+			errType: validation.ErrRequired,
+			message: "spec.phases[1].name: Required value",
+			details: errkit.ErrorDetails{"field": "spec.phases[1].name"},
+		},
+		{
+			name:    "Invalid",
+			err:     validation.Invalid(path, "Backup", "must be lowercase"),
+			errType: validation.ErrInvalid,
+			message: "spec.phases[1].name: Invalid value: must be lowercase",
+			details: errkit.ErrorDetails{"field": "spec.phases[1].name", "value": "Backup"},
+		},
+		{
+			name:    "NotSupported",
+			err:     validation.NotSupported(path, "restore", []string{"backup", "delete"}),
+			errType: validation.ErrNotSupported,
+			message: "spec.phases[1].name: Unsupported value",
+			details: errkit.ErrorDetails{"field": "spec.phases[1].name", "value": "restore", "supported": []string{"backup", "delete"}},
+		},
+		{
+			name:    "Duplicate",
+			err:     validation.Duplicate(path, "backup"),
+			errType: validation.ErrDuplicate,
+			message: "spec.phases[1].name: Duplicate value",
+			details: errkit.ErrorDetails{"field": "spec.phases[1].name", "value": "backup"},
+		},
+		{
+			name:    "TooLong",
+			err:     validation.TooLong(path, "backup", 3),
+			errType: validation.ErrTooLong,
+			message: "spec.phases[1].name: Too long: may not be longer than 3",
+			details: errkit.ErrorDetails{"field": "spec.phases[1].name", "value": "backup", "max_length": 3},
+		},
+	} {
+		t.Run("It should create "+tc.name+" errors", func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(tc.err.Error(), qt.Equals, tc.message)
+			c.Assert(errkit.Is(tc.err, tc.errType), qt.IsTrue)
+
+			var withDetails interface{ Details() errkit.ErrorDetails }
+			c.Assert(errkit.As(tc.err, &withDetails), qt.IsTrue)
+			c.Assert(withDetails.Details(), qt.DeepEquals, tc.details)
+		})
+	}
+
+	t.Run("It should capture the location of the caller", func(t *testing.T) {
+		c := qt.New(t)
+		_, _, line, _ := runtime.Caller(0)
+		err := validation.Required(path, "")
+
+		var located interface {
+			Location() (function, file string, line int)
+		}
+		c.Assert(errkit.As(err, &located), qt.IsTrue)
+		function, _, errLine := located.Location()
+		c.Assert(function, qt.Equals, "github.com/kanisterio/errkit/validation_test.TestErrors.func2")
+		c.Assert(errLine, qt.Equals, line+1)
+	})
+
+	t.Run("It should include field paths in the JSON of aggregated errors", func(t *testing.T) {
+		c := qt.New(t)
+		spec := validation.NewPath("spec")
+		errs := errkit.ErrorList{
+			validation.Required(spec.Child("blueprint"), ""),
+			validation.Invalid(spec.Child("phases").Index(0), 42, "must be an object"),
+		}
+
+		data, err := json.Marshal(errs.Err())
+		c.Assert(err, qt.IsNil)
+
+		var parsed struct {
+			Errors []struct {
+				Message string         `json:"message"`
+				Details map[string]any `json:"details"`
+			} `json:"errors"`
+		}
+		c.Assert(json.Unmarshal(data, &parsed), qt.IsNil)
+		c.Assert(parsed.Errors, qt.HasLen, 2)
+		c.Assert(parsed.Errors[0].Message, qt.Equals, "spec.blueprint: Required value")
+		c.Assert(parsed.Errors[0].Details["field"], qt.Equals, "spec.blueprint")
+		c.Assert(parsed.Errors[1].Details, qt.DeepEquals, map[string]any{"field": "spec.phases[0]", "value": float64(42)})
+	})
+}
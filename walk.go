@@ -0,0 +1,122 @@
+package errkit
+
+import "reflect"
+
+// WalkAction tells Walk how to proceed after visiting an error.
+type WalkAction int
+
+const (
+	// WalkContinue continues with causes of the visited error.
+	WalkContinue WalkAction = iota
+	// WalkSkip skips causes of the visited error, continuing with its siblings.
+	WalkSkip
+	// WalkStop stops walking.
+	WalkStop
+)
+
+// WalkFunc is called by Walk for every error of the tree. depth is 0 for the root error,
+// path holds indices of branches leading from the root to the visited error,
+// an error having a single cause has it at index 0.
+// The path is reused between calls, it has to be copied to be retained.
+type WalkFunc func(err error, depth int, path []int) WalkAction
+
+// Walk visits every error of the tree rooted at err depth-first, in pre-order.
+// Branches of the tree are causes returned by Unwrap() error, members of ErrorList,
+// errors returned by Unwrap() []error and errors wrapped by errkit errors, e.g. with WithStack,
+// which precede their causes. Errors already present on the path
+// to the visited error are not visited again, so cyclic chains are walked only once.
+func Walk(err error, fn WalkFunc) {
+	if err == nil {
+		return
+	}
+
+	w := walker{fn: fn, path: []int{}, visiting: map[visit]bool{}}
+	w.walk(err)
+}
+
+// Flatten returns the leaves of the error tree, i.e. errors which have no causes, in the order of Walk.
+func Flatten(err error) []error {
+	var leaves []error
+	Walk(err, func(err error, _ int, _ []int) WalkAction {
+		if len(errorBranches(err)) == 0 {
+			leaves = append(leaves, err)
+		}
+		return WalkContinue
+	})
+	return leaves
+}
+
+type walker struct {
+	fn       WalkFunc
+	path     []int
+	visiting map[visit]bool
+}
+
+// walk visits err and its causes, returns false when walking has to stop.
+func (w *walker) walk(err error) bool {
+	key, ok := errorIdentity(err)
+	if ok {
+		if w.visiting[key] {
+			return true
+		}
+		w.visiting[key] = true
+		defer delete(w.visiting, key)
+	}
+
+	switch w.fn(err, len(w.path), w.path) {
+	case WalkStop:
+		return false
+	case WalkSkip:
+		return true
+	}
+
+	for i, branch := range errorBranches(err) {
+		w.path = append(w.path, i)
+		next := w.walk(branch)
+		w.path = w.path[:len(w.path)-1]
+		if !next {
+			return false
+		}
+	}
+	return true
+}
+
+// errorBranches returns direct causes of err, which are not nil.
+func errorBranches(err error) []error {
+	var branches []error
+	switch e := err.(type) {
+	case *errkitError:
+		branches = []error{e.error, e.cause}
+	case ErrorList:
+		branches = e
+	case interface{ Unwrap() []error }:
+		branches = e.Unwrap()
+	case interface{ Unwrap() error }:
+		branches = []error{e.Unwrap()}
+	}
+
+	result := branches[:0:0]
+	for _, branch := range branches {
+		if branch != nil {
+			result = append(result, branch)
+		}
+	}
+	return result
+}
+
+// errorIdentity returns a key identifying errors referenced by pointers, the only errors which could form cycles.
+func errorIdentity(err error) (visit, bool) {
+	v := reflect.ValueOf(err)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return visit{}, false
+		}
+		key := visit{ptr: v.Pointer(), kind: v.Kind()}
+		if v.Kind() == reflect.Slice {
+			key.len = v.Len()
+		}
+		return key, true
+	}
+	return visit{}, false
+}
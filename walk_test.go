@@ -0,0 +1,142 @@
+package errkit_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+type cyclicError struct {
+	cause error
+}
+
+func (e *cyclicError) Error() string { return "cyclic error" }
+func (e *cyclicError) Unwrap() error { return e.cause }
+
+type visited struct {
+	Message string
+	Depth   int
+	Path    string
+}
+
+func walkAll(err error, fn func(err error) errkit.WalkAction) []visited {
+	var result []visited
+	errkit.Walk(err, func(err error, depth int, path []int) errkit.WalkAction {
+		message := err.Error()
+		if e, ok := err.(interface{ Message() string }); ok {
+			message = e.Message()
+		}
+		if _, ok := err.(errkit.ErrorList); ok {
+			message = "list"
+		}
+		result = append(result, visited{Message: message, Depth: depth, Path: fmt.Sprint(path)})
+		return fn(err)
+	})
+	return result
+}
+
+func continueWalk(error) errkit.WalkAction {
+	return errkit.WalkContinue
+}
+
+func TestWalk(t *testing.T) {
+	leaf1 := errkit.New("Leaf 1")
+	leaf2 := errors.New("Leaf 2")
+	leaf3 := errkit.NewSentinelErr("Leaf 3")
+	tree := errkit.Wrap(errkit.ErrorList{
+		leaf1,
+		errkit.Wrap(leaf2, "Branch"),
+		errors.Join(leaf3, nil),
+	}, "Root")
+
+	t.Run("It should visit every error depth-first", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(walkAll(tree, continueWalk), qt.DeepEquals, []visited{
+			{"Root", 0, "[]"},
+			{"list", 1, "[0]"},
+			{"Leaf 1", 2, "[0 0]"},
+			{"Branch", 2, "[0 1]"},
+			{"Leaf 2", 3, "[0 1 0]"},
+			{"Leaf 3", 2, "[0 2]"},
+			{"Leaf 3", 3, "[0 2 0]"},
+		})
+	})
+
+	t.Run("It should skip subtrees and stop", func(t *testing.T) {
+		c := qt.New(t)
+		skipped := walkAll(tree, func(err error) errkit.WalkAction {
+			if err.Error() == "Branch: Leaf 2" {
+				return errkit.WalkSkip
+			}
+			return errkit.WalkContinue
+		})
+		c.Assert(skipped, qt.HasLen, 6)
+		c.Assert(skipped[3].Message, qt.Equals, "Branch")
+		c.Assert(skipped[4].Message, qt.Equals, "Leaf 3")
+
+		stopped := walkAll(tree, func(err error) errkit.WalkAction {
+			if err == leaf1 {
+				return errkit.WalkStop
+			}
+			return errkit.WalkContinue
+		})
+		c.Assert(stopped, qt.HasLen, 3)
+	})
+
+	t.Run("It should visit errors of a cycle once", func(t *testing.T) {
+		c := qt.New(t)
+		cyclic := &cyclicError{}
+		cyclic.cause = errkit.Wrap(cyclic, "Wrapped")
+
+		c.Assert(walkAll(cyclic, continueWalk), qt.DeepEquals, []visited{
+			{"cyclic error", 0, "[]"},
+			{"Wrapped", 1, "[0]"},
+		})
+	})
+
+	t.Run("It should visit errors wrapped by errkit errors before their causes", func(t *testing.T) {
+		c := qt.New(t)
+		err := errkit.WithCause(leaf3, errkit.WithStack(leaf2))
+		c.Assert(walkAll(err, continueWalk), qt.DeepEquals, []visited{
+			{"Leaf 3", 0, "[]"},
+			{"Leaf 3", 1, "[0]"},
+			{"Leaf 2", 1, "[1]"},
+			{"Leaf 2", 2, "[1 0]"},
+		})
+	})
+
+	t.Run("It should not call the function for nil", func(t *testing.T) {
+		c := qt.New(t)
+		c.Assert(walkAll(nil, continueWalk), qt.HasLen, 0)
+	})
+}
+
+func TestFlatten(t *testing.T) {
+	t.Run("It should return leaves of the error tree", func(t *testing.T) {
+		c := qt.New(t)
+		leaf1 := errkit.New("Leaf 1")
+		leaf2 := errors.New("Leaf 2")
+		leaf3 := errkit.NewSentinelErr("Leaf 3")
+		err := errkit.Wrap(errkit.Append(leaf1, errkit.WithCause(leaf2, leaf3)), "Root")
+
+		leaves := errkit.Flatten(err)
+		c.Assert(leaves, qt.HasLen, 3)
+		c.Assert(leaves[0], qt.Equals, leaf1)
+		c.Assert(leaves[1], qt.Equals, leaf2)
+		c.Assert(leaves[2], qt.Equals, leaf3)
+		c.Assert(errkit.Flatten(nil), qt.IsNil)
+	})
+
+	t.Run("It should return leaves wrapped by errkit errors", func(t *testing.T) {
+		c := qt.New(t)
+		leaf1 := errors.New("Leaf 1")
+		leaf2 := errors.New("Leaf 2")
+		leaves := errkit.Flatten(errkit.WithStack(errkit.Append(leaf1, leaf2)))
+		c.Assert(leaves, qt.HasLen, 2)
+		c.Assert(leaves[0], qt.Equals, leaf1)
+		c.Assert(leaves[1], qt.Equals, leaf2)
+	})
+}
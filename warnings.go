@@ -0,0 +1,177 @@
+package errkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+var _ json.Marshaler = (*Warnings)(nil)
+var _ slog.LogValuer = (*Warnings)(nil)
+
+// Warnings collects non-fatal problems of an operation, e.g. a skipped volume or a deprecated field.
+// Warnings are errkit errors with details and locations, collected the same way as ErrorList,
+// so they could be returned along with a successful result and logged, rendered or serialized later.
+// It is safe to add warnings from multiple goroutines. The zero value is ready to use.
+//
+//	var warnings errkit.Warnings
+//	ctx = errkit.WithWarnings(ctx, &warnings)
+//	...
+//	errkit.Warn(ctx, "Volume is skipped", "pvc", pvc.Name)
+//	...
+//	return result, &warnings, nil
+//
+// In strict mode warnings are promoted to errors: they are returned by Add and Warn
+// instead of being collected, so the operation fails at the first warning.
+type Warnings struct {
+	// Strict enables the strict mode, it should be set before adding any warning.
+	Strict bool
+
+	mu   sync.Mutex
+	list ErrorList
+}
+
+// Add adds a warning with the given message and details, capturing the location of the caller.
+// It returns nil, unless in strict mode, where the warning is returned as an error instead of being collected.
+// Nothing is collected by a nil Warnings.
+func (w *Warnings) Add(message string, details ...any) error {
+	return w.add(newErrorWithConfig(DefaultConfig(), nil, message, nil, 2, ToErrorDetails(details)))
+}
+
+// AddErr adds err with the given details as a warning, capturing the location of the caller.
+// It returns nil, unless in strict mode, where the warning is returned as an error instead of being collected.
+// Nil errors are ignored.
+func (w *Warnings) AddErr(err error, details ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	return w.add(newErrorWithConfig(DefaultConfig(), err, "", nil, 2, ToErrorDetails(details)))
+}
+
+func (w *Warnings) add(warning error) error {
+	if w == nil {
+		return nil
+	}
+	if w.Strict {
+		return warning
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.list = append(w.list, warning)
+	return nil
+}
+
+// Len returns the number of collected warnings.
+func (w *Warnings) Len() int {
+	if w == nil {
+		return 0
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.list)
+}
+
+// List returns a copy of collected warnings, or nil if there are none.
+func (w *Warnings) List() ErrorList {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.list) == 0 {
+		return nil
+	}
+	return append(ErrorList(nil), w.list...)
+}
+
+// Promote returns collected warnings as an error, or nil if there are none.
+// It allows failing an operation having warnings, when they are not acceptable.
+func (w *Warnings) Promote() error {
+	return w.List().Err()
+}
+
+// String returns warnings in the same format as ErrorList does.
+func (w *Warnings) String() string {
+	return w.List().String()
+}
+
+// MarshalJSON serializes warnings in the same format as ErrorList does, with the summary of warnings as the message.
+func (w *Warnings) MarshalJSON() ([]byte, error) {
+	list := w.List()
+	l := DefaultLimits()
+	raw, err := list.marshalList(warningsMessage(len(list)), l, SchemaVersion, 0)
+	if err != nil || len(raw) <= l.maxBytes() {
+		return raw, err
+	}
+
+	return json.Marshal(jsonErrorList{
+		SchemaVersion: SchemaVersion,
+		Message:       warningsMessage(len(list)),
+		Errors:        []json.RawMessage{},
+		Truncated:     true,
+	})
+}
+
+// LogValue implements slog.LogValuer, so warnings are logged the same way as ErrorList,
+// with the summary of warnings as the message.
+func (w *Warnings) LogValue() slog.Value {
+	list := w.List()
+	return slog.GroupValue(
+		slog.String("message", warningsMessage(len(list))),
+		slog.Attr{Key: "errors", Value: list.membersLogValue()},
+	)
+}
+
+// Render draws warnings as a tree, the same way as Render draws an ErrorList.
+func (w *Warnings) Render(out io.Writer, opts RenderOptions) error {
+	list := w.List()
+	if len(list) == 0 {
+		return nil
+	}
+	return Render(out, warningList(list), opts)
+}
+
+// warningList is rendered the same way as ErrorList, with the summary of warnings as the message.
+type warningList ErrorList
+
+func (w warningList) Error() string   { return ErrorList(w).Error() }
+func (w warningList) Unwrap() []error { return w }
+
+func warningsMessage(n int) string {
+	if n == 1 {
+		return "1 warning has occurred"
+	}
+	return fmt.Sprintf("%d warnings have occurred", n)
+}
+
+type warningsKey struct{}
+
+// WithWarnings returns a copy of ctx, which makes Warn add warnings to w.
+func WithWarnings(ctx context.Context, w *Warnings) context.Context {
+	return context.WithValue(ctx, warningsKey{}, w)
+}
+
+// WarningsFromContext returns warnings attached to ctx with WithWarnings, or nil if there are none.
+func WarningsFromContext(ctx context.Context) *Warnings {
+	w, _ := ctx.Value(warningsKey{}).(*Warnings)
+	return w
+}
+
+// Warn adds a warning with the given message, details and details stored in ctx to warnings attached to ctx.
+// Details passed explicitly override details from ctx. Nothing is collected if ctx has no warnings attached.
+// It returns nil, unless attached warnings are in strict mode, where the warning is returned as an error.
+func Warn(ctx context.Context, message string, details ...any) error {
+	w := WarningsFromContext(ctx)
+	if w == nil {
+		return nil
+	}
+
+	return w.add(newErrorWithConfig(DefaultConfig(), nil, message, nil, 2, mergeDetails(ContextDetails(ctx), ToErrorDetails(details))))
+}
@@ -0,0 +1,119 @@
+package errkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/kanisterio/errkit"
+)
+
+func TestWarnings(t *testing.T) {
+	t.Run("It should collect warnings with details and locations", func(t *testing.T) {
+		c := qt.New(t)
+		var warnings errkit.Warnings
+		fnName, lineNumber := getStackInfo()
+		c.Assert(warnings.Add("Volume is skipped", "pvc", "data-0"), qt.IsNil)
+		c.Assert(warnings.AddErr(errPredefinedSentinelError, "field", "spec.deprecated"), qt.IsNil)
+		c.Assert(warnings.AddErr(nil), qt.IsNil)
+
+		list := warnings.List()
+		c.Assert(warnings.Len(), qt.Equals, 2)
+		checkErrorResult(t, list[0],
+			getMessageCheck("Volume is skipped"),
+			getLocationCheck(fnName, lineNumber+1),
+			getDetailsCheck(errkit.ErrorDetails{"pvc": "data-0"}),
+		)
+		checkErrorResult(t, list[1],
+			getLocationCheck(fnName, lineNumber+2),
+			getErrkitIsCheck(errPredefinedSentinelError),
+		)
+	})
+
+	t.Run("It should be safe to add warnings from multiple goroutines", func(t *testing.T) {
+		c := qt.New(t)
+		var warnings errkit.Warnings
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = warnings.Add("Volume is skipped", "index", i)
+			}()
+		}
+		wg.Wait()
+		c.Assert(warnings.Len(), qt.Equals, 20)
+	})
+
+	t.Run("It should collect warnings attached to a context", func(t *testing.T) {
+		c := qt.New(t)
+		var warnings errkit.Warnings
+		ctx := errkit.WithContextDetails(errkit.WithWarnings(context.Background(), &warnings), "namespace", "kanister")
+
+		fnName, lineNumber := getStackInfo()
+		c.Assert(errkit.Warn(ctx, "Volume is skipped", "pvc", "data-0"), qt.IsNil)
+		c.Assert(errkit.WarningsFromContext(ctx), qt.Equals, &warnings)
+		checkErrorResult(t, warnings.List()[0],
+			getLocationCheck(fnName, lineNumber+1),
+			getDetailsCheck(errkit.ErrorDetails{"namespace": "kanister", "pvc": "data-0"}),
+		)
+
+		c.Assert(errkit.Warn(context.Background(), "Lost warning"), qt.IsNil)
+		c.Assert(errkit.WarningsFromContext(context.Background()), qt.IsNil)
+	})
+
+	t.Run("It should render and serialize warnings like ErrorList", func(t *testing.T) {
+		c := qt.New(t)
+		var warnings errkit.Warnings
+		_ = warnings.Add("Volume is skipped", "pvc", "data-0")
+		_ = warnings.Add("Field is deprecated")
+
+		var buf bytes.Buffer
+		c.Assert(warnings.Render(&buf, errkit.RenderOptions{HideLocation: true}), qt.IsNil)
+		c.Assert(buf.String(), qt.Equals, "2 warnings have occurred\n├─ Volume is skipped\n│     pvc=data-0\n└─ Field is deprecated\n")
+		c.Assert(warnings.String(), qt.Equals, `["Volume is skipped","Field is deprecated"]`)
+
+		data, err := json.Marshal(&warnings)
+		c.Assert(err, qt.IsNil)
+		var decoded errkit.ErrorList
+		c.Assert(json.Unmarshal(data, &decoded), qt.IsNil)
+		c.Assert(decoded.Error(), qt.Equals, warnings.String())
+		c.Assert(string(data), qt.Contains, `"message":"2 warnings have occurred"`)
+
+		buf.Reset()
+		slog.New(slog.NewJSONHandler(&buf, nil)).Warn("Backup completed", "warnings", &warnings)
+		c.Assert(buf.String(), qt.Contains, `"warnings":{"message":"2 warnings have occurred","errors":{"0":{"message":"Volume is skipped"`)
+
+		var empty errkit.Warnings
+		data, err = json.Marshal(&empty)
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(data), qt.Equals, "null")
+	})
+
+	t.Run("It should promote warnings to errors", func(t *testing.T) {
+		c := qt.New(t)
+		var warnings errkit.Warnings
+		c.Assert(warnings.Promote(), qt.IsNil)
+
+		_ = warnings.Add("Volume is skipped")
+		c.Assert(warnings.Promote(), qt.ErrorMatches, "Volume is skipped")
+
+		strict := errkit.Warnings{Strict: true}
+		ctx := errkit.WithWarnings(context.Background(), &strict)
+		c.Assert(strict.Add("Volume is skipped"), qt.ErrorMatches, "Volume is skipped")
+		c.Assert(errkit.Warn(ctx, "Field is deprecated"), qt.ErrorMatches, "Field is deprecated")
+		c.Assert(strict.Len(), qt.Equals, 0)
+	})
+
+	t.Run("It should ignore warnings added to nil", func(t *testing.T) {
+		c := qt.New(t)
+		var warnings *errkit.Warnings
+		c.Assert(warnings.Add("Volume is skipped"), qt.IsNil)
+		c.Assert(warnings.Len(), qt.Equals, 0)
+		c.Assert(warnings.List() == nil, qt.IsTrue)
+	})
+}